@@ -97,6 +97,30 @@ func LoadCrontab() {
 				gocron.NewTask(getOnuTraffic),
 				gocron.WithSingletonMode(gocron.LimitModeReschedule),
 			)
+		case "get_port_traffic":
+			_, err = scheduler.NewJob(
+				gocron.CronJob(taskConfig.Schedule, false),
+				gocron.NewTask(getPortTraffic),
+				gocron.WithSingletonMode(gocron.LimitModeReschedule),
+			)
+		case "get_onu_uncfg":
+			_, err = scheduler.NewJob(
+				gocron.CronJob(taskConfig.Schedule, false),
+				gocron.NewTask(getOnuUncfg),
+				gocron.WithSingletonMode(gocron.LimitModeReschedule),
+			)
+		case "apply_onu_bandwidth":
+			_, err = scheduler.NewJob(
+				gocron.CronJob(taskConfig.Schedule, false),
+				gocron.NewTask(applyOnuBandwidth),
+				gocron.WithSingletonMode(gocron.LimitModeReschedule),
+			)
+		case "olt_discovery":
+			_, err = scheduler.NewJob(
+				gocron.CronJob(taskConfig.Schedule, false),
+				gocron.NewTask(oltDiscovery),
+				gocron.WithSingletonMode(gocron.LimitModeReschedule),
+			)
 		case "clean_onu_data":
 			_, err = scheduler.NewJob(
 				gocron.CronJob(taskConfig.Schedule, false),
@@ -173,6 +197,24 @@ func getOltInfo() {
 	}
 }
 
+func oltDiscovery() {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Logline("Recovered from panic <<olt_discovery>>: %v", r)
+		}
+	}()
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: PoolPgsql, Ctx: ctx}
+
+	// run actual task
+	if err := repo.OltDiscovery(db, "cronJob"); err != nil {
+		utils.Logline("Error on olt_discovery")
+	}
+}
+
 func oltAutoWrite() {
 	defer func() {
 		if r := recover(); r != nil {
@@ -227,6 +269,60 @@ func getOnuTraffic() {
 	}
 }
 
+func getPortTraffic() {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Logline("Recovered from panic <<get_port_traffic>>: %v", r)
+		}
+	}()
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: PoolPgsql, Ctx: ctx}
+
+	// run actual task
+	if err := repo.CronPortTraffic(db, "cronJob"); err != nil {
+		utils.Logline("Error on get_port_traffic")
+	}
+}
+
+func getOnuUncfg() {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Logline("Recovered from panic <<get_onu_uncfg>>: %v", r)
+		}
+	}()
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 40*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: PoolPgsql, Ctx: ctx}
+
+	// run actual task
+	if err := repo.CronOnuUncfg(db, "cronJob"); err != nil {
+		utils.Logline("Error on get_onu_uncfg")
+	}
+}
+
+func applyOnuBandwidth() {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Logline("Recovered from panic <<apply_onu_bandwidth>>: %v", r)
+		}
+	}()
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 280*time.Second)
+	defer cancel()
+	db := models.ConnMysqlPgsql{ConnPgsql: PoolPgsql, ConnMysql: PoolMysql, Ctx: ctx}
+
+	// run actual task
+	if err := repo.CronOnuBandwidth(db, "cronJob"); err != nil {
+		utils.Logline("Error on apply_onu_bandwidth")
+	}
+}
+
 func cleanOnuData() {
 	defer func() {
 		if r := recover(); r != nil {
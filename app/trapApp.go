@@ -0,0 +1,164 @@
+package app
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	g "github.com/gosnmp/gosnmp"
+	"ired.com/olt/models"
+	"ired.com/olt/repo"
+	"ired.com/olt/utils"
+)
+
+const (
+	trapWorkers      = 8               // traps processed at the same time
+	trapQueueSize    = 1000            // traps waiting for a worker, the ones received when it is full are dropped
+	trapHostsRefresh = 5 * time.Minute // interval to reload the olts and their snmp credentials
+)
+
+// trap received from a known olt waiting for a worker
+type trapJob struct {
+	packet *g.SnmpPacket
+	host   models.HostInfo
+}
+
+// olts allowed to send traps keyed by ip, and the snmpv3 credentials already added to the usm of the listener
+var trapSources = struct {
+	sync.RWMutex
+	hosts map[string]models.HostInfo
+	users map[models.SnmpAuth]bool
+}{hosts: map[string]models.HostInfo{}, users: map[models.SnmpAuth]bool{}}
+
+// StartTrapListener receives the snmp traps and informs of the olts on SNMP_TRAP_ADDRESS, disabled if the var is empty.
+// only the traps of the active olts with their community or snmpv3 user are processed, by a fixed number of workers
+func StartTrapListener() {
+	address := os.Getenv("SNMP_TRAP_ADDRESS")
+	if address == "" {
+		return
+	}
+
+	//users of snmpv3 of the olts, the traps v3 are authenticated and decrypted with them
+	usm := g.NewSnmpV3SecurityParametersTable(g.Logger{})
+	loadTrapSources(usm)
+	go func() {
+		for range time.Tick(trapHostsRefresh) {
+			loadTrapSources(usm)
+		}
+	}()
+
+	queue := make(chan trapJob, trapQueueSize)
+	for range trapWorkers {
+		go func() {
+			for job := range queue {
+				oltTrap(job.packet, job.host)
+			}
+		}()
+	}
+
+	listener := g.NewTrapListener()
+	listener.Params = &g.GoSNMP{
+		Transport:                   "udp",
+		Version:                     g.Version2c,
+		Timeout:                     2 * time.Second,
+		Retries:                     3,
+		TrapSecurityParametersTable: usm,
+	}
+	listener.OnNewTrap = func(packet *g.SnmpPacket, addr *net.UDPAddr) {
+		sourceIp := addr.IP.String()
+		if ip := addr.IP.To4(); ip != nil {
+			sourceIp = ip.String()
+		}
+
+		trapSources.RLock()
+		host, ok := trapSources.hosts[sourceIp]
+		trapSources.RUnlock()
+		if !ok {
+			utils.Logline("trap dropped from unknown source", sourceIp)
+			return
+		}
+		if !repo.TrapAuthorized(host, packet) {
+			utils.Logline("trap dropped with wrong community or snmpv3 user", sourceIp, host.Name)
+			return
+		}
+
+		// the listener is not blocked, with every worker busy the trap is dropped
+		select {
+		case queue <- trapJob{packet: packet, host: host}:
+		default:
+			utils.Logline("trap dropped, queue of traps full", sourceIp, host.Name)
+		}
+	}
+
+	go func() {
+		if err := listener.Listen(address); err != nil {
+			utils.Logline("Error listening snmp traps on "+address, err)
+		}
+	}()
+}
+
+// loadTrapSources reloads the olts allowed to send traps and adds the new snmpv3 users to the usm,
+// the credentials removed from an olt stay on the usm until a restart but its traps are rejected by repo.TrapAuthorized
+func loadTrapSources(usm *g.SnmpV3SecurityParametersTable) {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Logline("Recovered from panic <<trap_sources>>: %v", r)
+		}
+	}()
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: PoolPgsql, Ctx: ctx}
+
+	hosts, err := repo.TrapHosts(db)
+	if err != nil {
+		utils.Logline("Error loading olts of snmp traps", err)
+		return
+	}
+
+	trapSources.Lock()
+	defer trapSources.Unlock()
+	trapSources.hosts = hosts
+
+	for _, host := range hosts {
+		if host.Snmp.User == "" {
+			continue
+		}
+		key := host.Snmp
+		key.Community, key.TrapCommunity = "", ""
+		if trapSources.users[key] {
+			continue
+		}
+		security, _, err := utils.SnmpV3Security(host.Snmp)
+		if err != nil {
+			utils.Logline("Error on snmpv3 user of traps", host.Ip.String(), host.Name, err)
+			continue
+		}
+		if err := usm.Add(host.Snmp.User, security); err != nil {
+			utils.Logline("Error adding snmpv3 user of traps", host.Ip.String(), host.Name, err)
+			continue
+		}
+		trapSources.users[key] = true
+	}
+}
+
+func oltTrap(packet *g.SnmpPacket, host models.HostInfo) {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Logline("Recovered from panic <<olt_trap>>: %v", r)
+		}
+	}()
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: PoolPgsql, Ctx: ctx}
+
+	// run actual task
+	if err := repo.OltTrap(db, packet, host); err != nil {
+		utils.Logline("Error on olt_trap", host.Ip.String())
+	}
+}
@@ -0,0 +1,271 @@
+package catalog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	g "github.com/gosnmp/gosnmp"
+)
+
+// Metric is one column of a snmp table of the olt, described on oids.json
+type Metric struct {
+	Name     string            `json:"name"`     // name of the metric, unique per vendor
+	Models   []string          `json:"models"`   // models where the metric applies, empty for every model
+	Oid      string            `json:"oid"`      // oid of the column
+	Index    string            `json:"index"`    // rule to parse the index of the row: suffix (default) or last:N
+	Type     string            `json:"type"`     // text | mac | int | counter | float | status | datetime
+	Lower    bool              `json:"lower"`    // text values are changed to lowercase
+	Split    string            `json:"split"`    // text values are splitted and only the last part is used
+	Divisor  float64           `json:"divisor"`  // float values are divided by it
+	Bits     int               `json:"bits"`     // width of counter values to handle the wraps, 32 or 64, without it the one of the snmp type
+	Format   string            `json:"format"`   // format of float values, %.2f by default
+	Map      map[string]string `json:"map"`      // int and status values are translated with it, values not found are skipped
+	Item     string            `json:"item"`     // name of network.host_item, {index} and {last} are replaced
+	SnBase   string            `json:"snBase"`   // the sn of the item is the oid of the row without this prefix, the index if empty
+	Table    string            `json:"table"`    // detalle_int | detalle_text
+	Fallback string            `json:"fallback"` // metric walked when this one returns no rows
+}
+
+// Row is one parsed row of a metric
+type Row struct {
+	Index string
+	Value string
+}
+
+// OnuStatusCodes are the onu-status values stored on estadistica.detalle_int, every vendor uses the numbers of zte zxAnGponOnuPhaseState
+var OnuStatusCodes = map[string]string{
+	"logging":    "1",
+	"los":        "2",
+	"syncmib":    "3",
+	"working":    "4",
+	"online":     "4",
+	"dyinggasp":  "5",
+	"authfailed": "6",
+	"offline":    "7",
+}
+
+//go:embed oids.json
+var oidsJson []byte
+
+// metrics of every vendor
+var vendors map[string][]Metric
+
+var reFloat = regexp.MustCompile(`-?\d+(\.\d+)?`)
+
+func init() {
+	if err := json.Unmarshal(oidsJson, &vendors); err != nil {
+		panic(fmt.Sprintf("invalid oids.json: %v", err))
+	}
+}
+
+// Lookup returns the metric of the vendor, the one defined for the model is preferred over the generic one
+func Lookup(vendor string, model string, name string) (Metric, error) {
+	var generic *Metric
+	for i, metric := range vendors[vendor] {
+		if metric.Name != name {
+			continue
+		}
+		if len(metric.Models) == 0 {
+			if generic == nil {
+				generic = &vendors[vendor][i]
+			}
+			continue
+		}
+		for _, m := range metric.Models {
+			if m == model {
+				return metric, nil
+			}
+		}
+	}
+
+	if generic == nil {
+		return Metric{}, fmt.Errorf("metric %s not found on catalog of %s", name, vendor)
+	}
+	return *generic, nil
+}
+
+// Metrics returns the metrics of the vendor
+func Metrics(vendor string) []Metric {
+	return vendors[vendor]
+}
+
+// Vendors returns the vendors of the catalog
+func Vendors() []string {
+	var result []string
+	for vendor := range vendors {
+		result = append(result, vendor)
+	}
+	return result
+}
+
+// InTable reports if the oid is a row of the metric
+func (m Metric) InTable(oid string) bool {
+	return strings.HasPrefix(oid, m.Oid+".")
+}
+
+// ParseIndex returns the index of the row of oid
+func (m Metric) ParseIndex(oid string) (string, bool) {
+	if !m.InTable(oid) {
+		return "", false
+	}
+	index := strings.TrimPrefix(oid, m.Oid+".")
+
+	if strings.HasPrefix(m.Index, "last:") {
+		n, err := strconv.Atoi(strings.TrimPrefix(m.Index, "last:"))
+		if err != nil || n <= 0 {
+			return "", false
+		}
+		parts := strings.Split(index, ".")
+		if len(parts) < n {
+			return "", false
+		}
+		index = strings.Join(parts[len(parts)-n:], ".")
+	}
+
+	return index, index != ""
+}
+
+// ParseRow returns the row of the pdu, false if the pdu doesnt belong to the metric or its value must be skipped
+func (m Metric) ParseRow(value g.SnmpPDU) (Row, bool) {
+	index, ok := m.ParseIndex(value.Name)
+	if !ok {
+		return Row{}, false
+	}
+	data, ok := m.ParseValue(value)
+	if !ok {
+		return Row{}, false
+	}
+	return Row{Index: index, Value: data}, true
+}
+
+// ParseValue converts the value of the pdu with the type of the metric
+func (m Metric) ParseValue(value g.SnmpPDU) (string, bool) {
+	switch value.Type {
+	case g.NoSuchObject, g.NoSuchInstance, g.EndOfMibView, g.Null:
+		return "", false
+	}
+
+	switch m.Type {
+	case "mac":
+		// the mac address comes as raw bytes
+		if bytes, ok := value.Value.([]byte); ok && len(bytes) == 6 {
+			return fmt.Sprintf("%x", bytes), true
+		}
+		return m.text(value)
+	case "int", "counter":
+		number := g.ToBigInt(value.Value).String()
+		return m.mapped(number)
+	case "float":
+		return m.float(value)
+	case "datetime":
+		return m.datetime(value)
+	case "status":
+		if value.Type == g.OctetString {
+			state := strings.ToLower(strings.ReplaceAll(fmt.Sprintf("%s", value.Value), " ", ""))
+			code, ok := OnuStatusCodes[state]
+			return code, ok
+		}
+		return m.mapped(g.ToBigInt(value.Value).String())
+	default:
+		return m.text(value)
+	}
+}
+
+// CounterBits returns the width of the counter, the one set on the catalog or else the one of the snmp type of the value
+func (m Metric) CounterBits(value g.SnmpPDU) int {
+	if m.Bits != 0 {
+		return m.Bits
+	}
+	switch value.Type {
+	case g.Counter32, g.Gauge32, g.Uinteger32, g.Integer:
+		return 32
+	}
+	return 64
+}
+
+// CounterMax returns the max value of a counter of the width before it wraps to 0
+func CounterMax(bits int) uint64 {
+	if bits == 32 {
+		return math.MaxUint32
+	}
+	return math.MaxUint64
+}
+
+// ItemName returns the name of the item of the row
+func (m Metric) ItemName(index string) string {
+	parts := strings.Split(index, ".")
+	name := strings.ReplaceAll(m.Item, "{index}", index)
+	return strings.ReplaceAll(name, "{last}", parts[len(parts)-1])
+}
+
+// ItemSn returns the sn of the item of the row
+func (m Metric) ItemSn(oid string, index string) string {
+	if m.SnBase != "" {
+		return strings.TrimPrefix(oid, m.SnBase)
+	}
+	return index
+}
+
+func (m Metric) text(value g.SnmpPDU) (string, bool) {
+	text := strings.TrimSpace(fmt.Sprintf("%s", value.Value))
+	if m.Split != "" {
+		parts := strings.Split(text, m.Split)
+		text = strings.TrimSpace(parts[len(parts)-1])
+	}
+	if m.Lower {
+		text = strings.ToLower(text)
+	}
+	return text, text != ""
+}
+
+func (m Metric) mapped(value string) (string, bool) {
+	if m.Map == nil {
+		return value, true
+	}
+	data, ok := m.Map[value]
+	return data, ok
+}
+
+func (m Metric) float(value g.SnmpPDU) (string, bool) {
+	var number float64
+	if value.Type == g.OctetString {
+		match := reFloat.FindString(fmt.Sprintf("%s", value.Value))
+		if match == "" {
+			return "", false
+		}
+		number, _ = strconv.ParseFloat(match, 64)
+	} else {
+		number = float64(g.ToBigInt(value.Value).Int64())
+		if m.Divisor != 0 {
+			number = number / m.Divisor
+		}
+	}
+
+	if number == 0 {
+		return "0", true
+	}
+	format := m.Format
+	if format == "" {
+		format = "%.2f"
+	}
+	return fmt.Sprintf(format, number), true
+}
+
+// datetime converts DateAndTime of SNMPv2-TC (8 or 11 bytes) to 2006-01-02 15:04:05, the olts send 0000-00-00 when the event never happened.
+// the values of other lengths are dates already sent as text
+func (m Metric) datetime(value g.SnmpPDU) (string, bool) {
+	bytes, ok := value.Value.([]byte)
+	if !ok || (len(bytes) != 8 && len(bytes) != 11) {
+		return m.text(value)
+	}
+	year := int(bytes[0])<<8 | int(bytes[1])
+	if year == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", year, bytes[2], bytes[3], bytes[4], bytes[5], bytes[6]), true
+}
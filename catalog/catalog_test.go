@@ -0,0 +1,153 @@
+package catalog
+
+import (
+	"math"
+	"testing"
+
+	g "github.com/gosnmp/gosnmp"
+)
+
+func TestLookup(t *testing.T) {
+	vendors["test"] = []Metric{
+		{Name: "onu-rx", Oid: ".1.1"},
+		{Name: "onu-rx", Oid: ".1.2", Models: []string{"c300"}},
+		{Name: "onu-rx", Oid: ".1.3"},
+	}
+	defer delete(vendors, "test")
+
+	tests := []struct {
+		vendor  string
+		model   string
+		name    string
+		wantOid string
+		wantErr bool
+	}{
+		{"test", "", "onu-rx", ".1.1", false},
+		{"test", "c320", "onu-rx", ".1.1", false},
+		{"test", "c300", "onu-rx", ".1.2", false},
+		{"test", "", "onu-tx", "", true},
+		{"other", "", "onu-rx", "", true},
+		{"zte", "", "onu-name", ".1.3.6.1.4.1.3902.1082.500.10.2.3.3.1.2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.vendor+"/"+tt.model+"/"+tt.name, func(t *testing.T) {
+			metric, err := Lookup(tt.vendor, tt.model, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lookup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if metric.Oid != tt.wantOid {
+				t.Errorf("Lookup() oid = %q, want %q", metric.Oid, tt.wantOid)
+			}
+		})
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	tests := []struct {
+		name   string
+		metric Metric
+		oid    string
+		want   string
+		wantOk bool
+	}{
+		{"suffix", Metric{Oid: ".1.3.6.1"}, ".1.3.6.1.268501248.1", "268501248.1", true},
+		{"other table", Metric{Oid: ".1.3.6.1"}, ".1.3.6.2.1", "", false},
+		{"prefix of the oid", Metric{Oid: ".1.3.6.1"}, ".1.3.6.10.1", "", false},
+		{"without index", Metric{Oid: ".1.3.6.1"}, ".1.3.6.1.", "", false},
+		{"last 1", Metric{Oid: ".1.3.6.1", Index: "last:1"}, ".1.3.6.1.5.12", "12", true},
+		{"last 2", Metric{Oid: ".1.3.6.1", Index: "last:2"}, ".1.3.6.1.9.5.12", "5.12", true},
+		{"last longer than index", Metric{Oid: ".1.3.6.1", Index: "last:3"}, ".1.3.6.1.5.12", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.metric.ParseIndex(tt.oid)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("ParseIndex(%q) = %q, %v, want %q, %v", tt.oid, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		metric Metric
+		value  g.SnmpPDU
+		want   string
+		wantOk bool
+	}{
+		{"text", Metric{Type: "text"}, g.SnmpPDU{Type: g.OctetString, Value: []byte(" ONU-1 ")}, "ONU-1", true},
+		{"text empty", Metric{Type: "text"}, g.SnmpPDU{Type: g.OctetString, Value: []byte(" ")}, "", false},
+		{"text split lower", Metric{Type: "text", Split: ",", Lower: true}, g.SnmpPDU{Type: g.OctetString, Value: []byte("1,ZTEGC8A1B2C3")}, "ztegc8a1b2c3", true},
+		{"mac", Metric{Type: "mac"}, g.SnmpPDU{Type: g.OctetString, Value: []byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}}, "001a2b3c4d5e", true},
+		{"int", Metric{Type: "int"}, g.SnmpPDU{Type: g.Integer, Value: 42}, "42", true},
+		{"int mapped", Metric{Type: "int", Map: map[string]string{"1": "4"}}, g.SnmpPDU{Type: g.Integer, Value: 1}, "4", true},
+		{"int not mapped", Metric{Type: "int", Map: map[string]string{"1": "4"}}, g.SnmpPDU{Type: g.Integer, Value: 2}, "", false},
+		{"counter64", Metric{Type: "counter"}, g.SnmpPDU{Type: g.Counter64, Value: uint64(math.MaxUint64)}, "18446744073709551615", true},
+		{"float divisor", Metric{Type: "float", Divisor: 1000}, g.SnmpPDU{Type: g.Integer, Value: -21345}, "-21.34", true},
+		{"float zero", Metric{Type: "float", Divisor: 100}, g.SnmpPDU{Type: g.Integer, Value: 0}, "0", true},
+		{"float text", Metric{Type: "float"}, g.SnmpPDU{Type: g.OctetString, Value: []byte("-19.52(dBm)")}, "-19.52", true},
+		{"float text without number", Metric{Type: "float"}, g.SnmpPDU{Type: g.OctetString, Value: []byte("N/A")}, "", false},
+		{"status text", Metric{Type: "status"}, g.SnmpPDU{Type: g.OctetString, Value: []byte("Dying Gasp")}, "5", true},
+		{"status int", Metric{Type: "status", Map: map[string]string{"3": "4"}}, g.SnmpPDU{Type: g.Integer, Value: 3}, "4", true},
+		{"no such instance", Metric{Type: "int"}, g.SnmpPDU{Type: g.NoSuchInstance}, "", false},
+		{"end of mib", Metric{Type: "text"}, g.SnmpPDU{Type: g.EndOfMibView}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.metric.ParseValue(tt.value)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("ParseValue() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestParseValueDatetime(t *testing.T) {
+	metric := Metric{Type: "datetime"}
+	tests := []struct {
+		name   string
+		value  []byte
+		want   string
+		wantOk bool
+	}{
+		{"8 bytes", []byte{0x07, 0xe8, 3, 15, 10, 4, 5, 0}, "2024-03-15 10:04:05", true},
+		{"11 bytes with timezone", []byte{0x07, 0xe8, 12, 31, 23, 59, 59, 0, '+', 5, 0}, "2024-12-31 23:59:59", true},
+		{"never happened", []byte{0, 0, 0, 0, 0, 0, 0, 0}, "", false},
+		{"text", []byte("2024-03-15 10:04:05"), "2024-03-15 10:04:05", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := metric.ParseValue(g.SnmpPDU{Type: g.OctetString, Value: tt.value})
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("ParseValue() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestCounterBits(t *testing.T) {
+	tests := []struct {
+		name   string
+		metric Metric
+		value  g.SnmpPDU
+		want   int
+		max    uint64
+	}{
+		{"counter32", Metric{Type: "counter"}, g.SnmpPDU{Type: g.Counter32}, 32, math.MaxUint32},
+		{"gauge32", Metric{Type: "counter"}, g.SnmpPDU{Type: g.Gauge32}, 32, math.MaxUint32},
+		{"counter64", Metric{Type: "counter"}, g.SnmpPDU{Type: g.Counter64}, 64, math.MaxUint64},
+		{"bits of the catalog", Metric{Type: "counter", Bits: 32}, g.SnmpPDU{Type: g.Counter64}, 32, math.MaxUint32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bits := tt.metric.CounterBits(tt.value)
+			if bits != tt.want {
+				t.Errorf("CounterBits() = %d, want %d", bits, tt.want)
+			}
+			if got := CounterMax(bits); got != tt.max {
+				t.Errorf("CounterMax(%d) = %d, want %d", bits, got, tt.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package clitemplate
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// templates of the cli commands of every vendor as <vendor>/<name>.tmpl, the ones of a model as <vendor>/<model>/<name>.tmpl
+// have priority. every line rendered is one command, the empty lines are skipped
+//
+//go:embed zte vsol cdata
+var files embed.FS
+
+// Render renders the template name of the vendor and model with data and returns the commands, a key of data
+// used by the template and not found is an error
+func Render(vendor string, model string, name string, data map[string]any) ([]string, error) {
+	file, err := lookup(vendor, model, name)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := files.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cli template %s: %w", file, err)
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cli template %s: %w", file, err)
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return nil, fmt.Errorf("error rendering cli template %s: %w", file, err)
+	}
+
+	var commands []string
+	for _, line := range strings.Split(buffer.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			commands = append(commands, line)
+		}
+	}
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("cli template %s rendered no commands", file)
+	}
+
+	return commands, nil
+}
+
+// Names returns the names of the templates of the vendor, the ones of a model included
+func Names(vendor string) []string {
+	unique := map[string]bool{}
+	fs.WalkDir(files, vendor, func(file string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && path.Ext(file) == ".tmpl" {
+			unique[strings.TrimSuffix(path.Base(file), ".tmpl")] = true
+		}
+		return nil
+	})
+
+	var names []string
+	for name := range unique {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup returns the file of the template, the one of the model is preferred over the generic one
+func lookup(vendor string, model string, name string) (string, error) {
+	if !validDir(vendor) || !validDir(name) {
+		return "", fmt.Errorf("there is no cli template %s for vendor %s", name, vendor)
+	}
+	candidates := []string{path.Join(vendor, name+".tmpl")}
+	if validDir(model) {
+		candidates = append([]string{path.Join(vendor, model, name+".tmpl")}, candidates...)
+	}
+	for _, file := range candidates {
+		if !fs.ValidPath(file) {
+			continue
+		}
+		if _, err := fs.Stat(files, file); err == nil {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("there is no cli template %s for vendor %s", name, vendor)
+}
+
+// validDir reports if the vendor, model or name is only one element of the path, a model like ../vsol cant pick the templates of another vendor
+func validDir(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
@@ -0,0 +1,97 @@
+package clitemplate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		vendor   string
+		model    string
+		template string
+		data     map[string]any
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "register", vendor: "zte", template: "onu_register",
+			data: map[string]any{"port": "1/2/1", "onu_id": 5, "onu_type": "ZTE-F660", "sn": "ZTEGC8A1B2C3"},
+			want: []string{"configure terminal", "interface gpon-olt_1/2/1", "onu 5 type ZTE-F660 sn ZTEGC8A1B2C3", "exit"},
+		},
+		{
+			name: "bandwidth up and down", vendor: "zte", template: "onu_bandwidth",
+			data: map[string]any{"interface": "gpon-onu_1/2/1:5", "up_profile": "UP-50M", "down_profile": "DW-100M"},
+			want: []string{"configure terminal", "interface gpon-onu_1/2/1:5", "tcont 1 profile UP-50M", "gemport 1 traffic-limit downstream DW-100M", "end"},
+		},
+		{
+			name: "bandwidth without down removes the limit", vendor: "zte", template: "onu_bandwidth",
+			data: map[string]any{"interface": "gpon-onu_1/2/1:5", "up_profile": "UP-50M", "down_profile": ""},
+			want: []string{"configure terminal", "interface gpon-onu_1/2/1:5", "tcont 1 profile UP-50M", "no gemport 1 traffic-limit downstream", "end"},
+		},
+		{
+			name: "bandwidth verify without down", vendor: "zte", template: "onu_bandwidth_verify",
+			data: map[string]any{"up_profile": "UP-50M", "down_profile": ""},
+			want: []string{"tcont 1 profile UP-50M", "no gemport 1 traffic-limit downstream"},
+		},
+		{
+			name: "model without override uses the generic one", vendor: "zte", model: "c320", template: "clock",
+			want: []string{"show clock"},
+		},
+		{
+			name: "template of the vendor", vendor: "vsol", template: "clock",
+			want: []string{"show time"},
+		},
+		{
+			name: "missing key", vendor: "zte", template: "onu_register",
+			data:    map[string]any{"port": "1/2/1", "onu_id": 5},
+			wantErr: true,
+		},
+		{
+			name: "unknown template", vendor: "vsol", template: "onu_register",
+			wantErr: true,
+		},
+		{
+			name: "unknown vendor", vendor: "huawei", template: "clock",
+			wantErr: true,
+		},
+		{
+			name: "model with a path to another vendor", vendor: "zte", model: "../vsol", template: "clock",
+			want: []string{"show clock"},
+		},
+		{
+			name: "name with a path", vendor: "zte", template: "../vsol/clock",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.vendor, tt.model, tt.template, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		vendor string
+		want   []string
+	}{
+		{"vsol", []string{"clock", "fan", "save_config", "uncfg_onus"}},
+		{"cdata", []string{"clock", "cpu", "fan", "save_config", "temperature", "uncfg_onus"}},
+		{"huawei", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.vendor, func(t *testing.T) {
+			if got := Names(tt.vendor); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Names(%q) = %q, want %q", tt.vendor, got, tt.want)
+			}
+		})
+	}
+}
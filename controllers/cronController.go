@@ -18,10 +18,14 @@ func CronRoutes(r *gin.Engine) {
 		cron.GET("/olt-getclock", middlewares.BasicAuth(), getClock)
 		cron.GET("/olt-getinfo", middlewares.BasicAuth(), oltInfo)
 		cron.GET("/olt-autowrite", middlewares.BasicAuth(), oltAutoWrite)
+		cron.GET("/olt-discovery", middlewares.BasicAuth(), oltDiscovery)
 		cron.GET("/olt-cleaning", middlewares.BasicAuth(), oltCleaning)
 		cron.GET("/onu-getinfo", middlewares.BasicAuth(), onuInfo)
 		cron.GET("/onu-traffic", middlewares.BasicAuth(), onuTraffic)
 		cron.GET("/onu-cleaning", middlewares.BasicAuth(), onuCleaning)
+		cron.GET("/port-traffic", middlewares.BasicAuth(), portTraffic)
+		cron.GET("/onu-uncfg", middlewares.BasicAuth(), onuUncfg)
+		cron.GET("/onu-bandwidth", middlewares.BasicAuth(), onuBandwidthCron)
 	}
 }
 
@@ -112,6 +116,35 @@ func oltAutoWrite(c *gin.Context) {
 	)
 }
 
+// @Summary 			Run the task olt_discovery
+// @Description 	run cron to detect vendor and model of the olts via sysObjectID and sysDescr
+// @Tags 					Crons
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Success 			200 {object} models.SuccessResponse
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/cron/olt-discovery [get]
+func oltDiscovery(c *gin.Context) {
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: app.PoolPgsql, Ctx: ctx}
+
+	if err := repo.OltDiscovery(db, "restApi"); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Cron Executed ok"},
+	)
+}
+
 // @Summary 			Run the task clean_olt_data
 // @Description 	run cron to clean old data relating olt in DB
 // @Tags 					Crons
@@ -233,3 +266,90 @@ func onuCleaning(c *gin.Context) {
 		models.SuccessResponse{Notice: "Cron Executed ok"},
 	)
 }
+
+// @Summary 			Run the task get_port_traffic
+// @Description 	run cron to get traffic, status and errors of the pon ports and uplinks of the olts
+// @Tags 					Crons
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Success 			200 {object} models.SuccessResponse
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/cron/port-traffic [get]
+func portTraffic(c *gin.Context) {
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: app.PoolPgsql, Ctx: ctx}
+
+	if err := repo.CronPortTraffic(db, "restApi"); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Cron Executed ok"},
+	)
+}
+
+// @Summary 			Run the task get_onu_uncfg
+// @Description 	run cron to get the onus connected but not authorized on all olts
+// @Tags 					Crons
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Success 			200 {object} models.SuccessResponse
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/cron/onu-uncfg [get]
+func onuUncfg(c *gin.Context) {
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 40*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: app.PoolPgsql, Ctx: ctx}
+
+	if err := repo.CronOnuUncfg(db, "restApi"); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Cron Executed ok"},
+	)
+}
+
+// @Summary 			Run the task apply_onu_bandwidth
+// @Description 	run cron to apply the profiles of bandwidth of the onus pending on network.onu_bandwidth
+// @Tags 					Crons
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Success 			200 {object} models.SuccessResponse
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/cron/onu-bandwidth [get]
+func onuBandwidthCron(c *gin.Context) {
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+	db := models.ConnMysqlPgsql{ConnMysql: app.PoolMysql, ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	if err := repo.CronOnuBandwidth(db, "restApi"); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Cron Executed ok"},
+	)
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ired.com/olt/app"
+	"ired.com/olt/middlewares"
+	"ired.com/olt/models"
+	"ired.com/olt/repo"
+)
+
+func OltRoutes(r *gin.Engine) {
+	olt := r.Group("/olt")
+	{
+		olt.GET("/detections", middlewares.BasicAuth(), oltDetections)
+		olt.GET("/uncfg-onus", middlewares.BasicAuth(), uncfgOnus)
+		olt.GET("/cli-templates", middlewares.BasicAuth(), cliTemplates)
+		olt.POST("/cli-render", middlewares.BasicAuth(), cliRender)
+	}
+}
+
+// @Summary 			List olts with vendor or model mismatch
+// @Description 	olts where the vendor or model detected by olt_discovery disagrees with the configured data in network.host
+// @Tags 					Olts
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Success 			200 {object} models.SuccessResponse{record=[]models.OltDetection}
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/olt/detections [get]
+func oltDetections(c *gin.Context) {
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: app.PoolPgsql, Ctx: ctx}
+
+	detections, err := repo.GetOltDetections(db)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Query executed ok", Record: detections},
+	)
+}
+
+// @Summary 			List unconfigured onus
+// @Description 	onus connected to the pon ports but not authorized, seen by the task get_onu_uncfg on the last minutes
+// @Tags 					Olts
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Param 				host_id query string false "id of the olt on network.host"
+// @Param 				sn query string false "sn or part of it"
+// @Param 				minutes query int false "minutes since the onu was seen, 10 by default"
+// @Success 			200 {object} models.SuccessResponse{record=[]models.UncfgOnu}
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/olt/uncfg-onus [get]
+func uncfgOnus(c *gin.Context) {
+	minutes, err := strconv.Atoi(c.DefaultQuery("minutes", "10"))
+	if err != nil || minutes <= 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: "minutes must be a positive number"},
+		)
+		return
+	}
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: app.PoolPgsql, Ctx: ctx}
+
+	onus, err := repo.GetUncfgOnus(db, c.Query("host_id"), strings.TrimSpace(c.Query("sn")), minutes)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Query executed ok", Record: onus},
+	)
+}
+
+// @Summary 			List cli templates
+// @Description 	names of the templates of cli commands of the vendor
+// @Tags 					Olts
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Param 				vendor query string true "zte, vsol or cdata"
+// @Success 			200 {object} models.SuccessResponse{record=[]string}
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/olt/cli-templates [get]
+func cliTemplates(c *gin.Context) {
+	names := repo.GetCliTemplates(c.Query("vendor"))
+	if len(names) == 0 {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: "there are no cli templates for vendor " + c.Query("vendor")},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Query executed ok", Record: names},
+	)
+}
+
+// @Summary 			Render a cli template
+// @Description 	dry run of a template of cli commands, returns the commands that would be sent to the olt without connecting to it
+// @Tags 					Olts
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Param 				render body models.CliRender true "template and its data"
+// @Success 			200 {object} models.SuccessResponse{record=models.CliRendered}
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/olt/cli-render [post]
+func cliRender(c *gin.Context) {
+	var request models.CliRender
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: app.PoolPgsql, Ctx: ctx}
+
+	rendered, err := repo.RenderCliTemplate(db, request)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Template rendered ok", Record: rendered},
+	)
+}
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ired.com/olt/app"
+	"ired.com/olt/middlewares"
+	"ired.com/olt/models"
+	"ired.com/olt/repo"
+)
+
+func OnuRoutes(r *gin.Engine) {
+	onu := r.Group("/onu")
+	{
+		onu.POST("", middlewares.BasicAuth(), provisionOnu)
+		onu.DELETE("", middlewares.BasicAuth(), deprovisionOnu)
+		onu.POST("/move", middlewares.BasicAuth(), moveOnu)
+		onu.POST("/action", middlewares.BasicAuth(), onuAction)
+		onu.GET("/bandwidth", middlewares.BasicAuth(), onuBandwidth)
+		onu.PUT("/bandwidth", middlewares.BasicAuth(), setOnuBandwidth)
+	}
+}
+
+// @Summary 			Provision an onu
+// @Description 	authorize the onu on the pon port of the olt, configure its service port and name it as <name>_-_<oldid>, the onu is left pending to sync on estaciones_onu so olt_autowrite saves the config
+// @Tags 					Onus
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Param 				onu body models.OnuProvision true "onu to provision"
+// @Success 			200 {object} models.SuccessResponse{record=models.OnuProvisioned}
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/onu [post]
+func provisionOnu(c *gin.Context) {
+	var onu models.OnuProvision
+	if err := c.ShouldBindJSON(&onu); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+	defer cancel()
+	db := models.ConnMysqlPgsql{ConnMysql: app.PoolMysql, ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	provisioned, err := repo.ProvisionOnu(db, onu)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Onu provisioned ok", Record: provisioned},
+	)
+}
+
+// @Summary 			Delete an onu
+// @Description 	remove the onu from the olt, its host items are deactivated and kept with the customer oldid, the change is recorded on network.onu_change
+// @Tags 					Onus
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Param 				onu body models.OnuDeprovision true "onu to delete"
+// @Success 			200 {object} models.SuccessResponse{record=models.OnuChange}
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/onu [delete]
+func deprovisionOnu(c *gin.Context) {
+	var onu models.OnuDeprovision
+	if err := c.ShouldBindJSON(&onu); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	db := models.ConnMysqlPgsql{ConnMysql: app.PoolMysql, ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	change, err := repo.DeprovisionOnu(db, onu)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Onu deleted ok", Record: change},
+	)
+}
+
+// @Summary 			Move an onu
+// @Description 	remove the onu from the olt from_host_id and provision it on the pon port of host_id, its host items are re-pointed to the new olt and index, the change is recorded on network.onu_change
+// @Tags 					Onus
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Param 				onu body models.OnuMove true "onu to move"
+// @Success 			200 {object} models.SuccessResponse{record=models.OnuChange}
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/onu/move [post]
+func moveOnu(c *gin.Context) {
+	var onu models.OnuMove
+	if err := c.ShouldBindJSON(&onu); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+	db := models.ConnMysqlPgsql{ConnMysql: app.PoolMysql, ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	change, err := repo.MoveOnu(db, onu)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Onu moved ok", Record: change},
+	)
+}
+
+// @Summary 			Run an action on an onu
+// @Description 	reboot, disable (suspension of the service), enable or reset to factory config the onu found by oldid or sn, the outcome is recorded on network.onu_action
+// @Tags 					Onus
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Param 				action body models.OnuActionRequest true "onu and action"
+// @Success 			200 {object} models.SuccessResponse{record=models.OnuActionResult}
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/onu/action [post]
+func onuAction(c *gin.Context) {
+	var request models.OnuActionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: app.PoolPgsql, Ctx: ctx}
+
+	result, err := repo.RunOnuAction(db, request)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Action executed ok", Record: result},
+	)
+}
+
+// @Summary 			List profiles of bandwidth of onus
+// @Description 	desired profiles of bandwidth of the onus and when they were applied on the olt
+// @Tags 					Onus
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Param 				oldid query string false "id of estaciones_onu"
+// @Success 			200 {object} models.SuccessResponse{record=[]models.OnuBandwidth}
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/onu/bandwidth [get]
+func onuBandwidth(c *gin.Context) {
+	//set variables for handling pgsql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	db := models.ConnDb{Conn: app.PoolPgsql, Ctx: ctx}
+
+	states, err := repo.GetOnuBandwidth(db, c.Query("oldid"))
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Query executed ok", Record: states},
+	)
+}
+
+// @Summary 			Set the profiles of bandwidth of an onu
+// @Description 	store the desired upstream (tcont) and downstream (traffic limit) profiles of the onu and apply them on the olt, if the olt fails they are retried by the task apply_onu_bandwidth
+// @Tags 					Onus
+// @Accept 				json
+// @Produce 			json
+// @Security 			BasicAuth
+// @Param 				bandwidth body models.OnuBandwidthRequest true "profiles of the onu"
+// @Success 			200 {object} models.SuccessResponse{record=models.OnuBandwidth}
+// @Failure 			400 {object} models.ErrorResponse
+// @Router 				/onu/bandwidth [put]
+func setOnuBandwidth(c *gin.Context) {
+	var request models.OnuBandwidthRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	//set variables for handling pgsql and mysql conn
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	db := models.ConnMysqlPgsql{ConnMysql: app.PoolMysql, ConnPgsql: app.PoolPgsql, Ctx: ctx}
+
+	state, err := repo.SetOnuBandwidth(db, request)
+	if err != nil {
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			models.ErrorResponse{Error: err.Error()},
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		models.SuccessResponse{Notice: "Profiles applied ok", Record: state},
+	)
+}
@@ -87,6 +87,40 @@ const docTemplate = `{
                 }
             }
         },
+        "/cron/olt-discovery": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "run cron to detect vendor and model of the olts via sysObjectID and sysDescr",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task olt_discovery",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/cron/olt-getclock": {
             "get": {
                 "security": [
@@ -155,6 +189,40 @@ const docTemplate = `{
                 }
             }
         },
+        "/cron/onu-bandwidth": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "run cron to apply the profiles of bandwidth of the onus pending on network.onu_bandwidth",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task apply_onu_bandwidth",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/cron/onu-cleaning": {
             "get": {
                 "security": [
@@ -256,22 +324,1049 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {}
+        },
+        "/cron/onu-uncfg": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "run cron to get the onus connected but not authorized on all olts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task get_onu_uncfg",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
             }
         },
-        "models.SuccessResponse": {
-            "type": "object",
-            "properties": {
-                "notice": {
-                    "type": "string"
-                },
-                "record": {}
+        "/cron/port-traffic": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "run cron to get traffic, status and errors of the pon ports and uplinks of the olts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crons"
+                ],
+                "summary": "Run the task get_port_traffic",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/olt/cli-render": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "dry run of a template of cli commands, returns the commands that would be sent to the olt without connecting to it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Olts"
+                ],
+                "summary": "Render a cli template",
+                "parameters": [
+                    {
+                        "description": "template and its data",
+                        "name": "render",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CliRender"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.CliRendered"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/olt/cli-templates": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "names of the templates of cli commands of the vendor",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Olts"
+                ],
+                "summary": "List cli templates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "zte, vsol or cdata",
+                        "name": "vendor",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/olt/detections": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "olts where the vendor or model detected by olt_discovery disagrees with the configured data in network.host",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Olts"
+                ],
+                "summary": "List olts with vendor or model mismatch",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.OltDetection"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/olt/uncfg-onus": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "onus connected to the pon ports but not authorized, seen by the task get_onu_uncfg on the last minutes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Olts"
+                ],
+                "summary": "List unconfigured onus",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of the olt on network.host",
+                        "name": "host_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sn or part of it",
+                        "name": "sn",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "minutes since the onu was seen, 10 by default",
+                        "name": "minutes",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.UncfgOnu"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/onu": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "authorize the onu on the pon port of the olt, configure its service port and name it as \u003cname\u003e_-_\u003coldid\u003e, the onu is left pending to sync on estaciones_onu so olt_autowrite saves the config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Onus"
+                ],
+                "summary": "Provision an onu",
+                "parameters": [
+                    {
+                        "description": "onu to provision",
+                        "name": "onu",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OnuProvision"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.OnuProvisioned"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "remove the onu from the olt, its host items are deactivated and kept with the customer oldid, the change is recorded on network.onu_change",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Onus"
+                ],
+                "summary": "Delete an onu",
+                "parameters": [
+                    {
+                        "description": "onu to delete",
+                        "name": "onu",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OnuDeprovision"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.OnuChange"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/onu/action": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "reboot, disable (suspension of the service), enable or reset to factory config the onu found by oldid or sn, the outcome is recorded on network.onu_action",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Onus"
+                ],
+                "summary": "Run an action on an onu",
+                "parameters": [
+                    {
+                        "description": "onu and action",
+                        "name": "action",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OnuActionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.OnuActionResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/onu/bandwidth": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "desired profiles of bandwidth of the onus and when they were applied on the olt",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Onus"
+                ],
+                "summary": "List profiles of bandwidth of onus",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of estaciones_onu",
+                        "name": "oldid",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.OnuBandwidth"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "store the desired upstream (tcont) and downstream (traffic limit) profiles of the onu and apply them on the olt, if the olt fails they are retried by the task apply_onu_bandwidth",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Onus"
+                ],
+                "summary": "Set the profiles of bandwidth of an onu",
+                "parameters": [
+                    {
+                        "description": "profiles of the onu",
+                        "name": "bandwidth",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OnuBandwidthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.OnuBandwidth"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/onu/move": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "remove the onu from the olt from_host_id and provision it on the pon port of host_id, its host items are re-pointed to the new olt and index, the change is recorded on network.onu_change",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Onus"
+                ],
+                "summary": "Move an onu",
+                "parameters": [
+                    {
+                        "description": "onu to move",
+                        "name": "onu",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OnuMove"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "record": {
+                                            "$ref": "#/definitions/models.OnuChange"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.CliRender": {
+            "type": "object",
+            "required": [
+                "template"
+            ],
+            "properties": {
+                "data": {
+                    "description": "keys used by the template, {\"port\":\"1/2/1\",\"onu_id\":7}",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "host_id": {
+                    "description": "id of the olt on network.host",
+                    "type": "string"
+                },
+                "model": {
+                    "description": "model with its own templates, empty for the generic ones",
+                    "type": "string"
+                },
+                "template": {
+                    "description": "name of the template, onu_register",
+                    "type": "string"
+                },
+                "vendor": {
+                    "description": "zte, vsol or cdata",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CliRendered": {
+            "type": "object",
+            "properties": {
+                "commands": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "model": {
+                    "type": "string"
+                },
+                "template": {
+                    "type": "string"
+                },
+                "vendor": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {}
+            }
+        },
+        "models.OltDetection": {
+            "type": "object",
+            "properties": {
+                "configured_model": {
+                    "type": "string"
+                },
+                "configured_vendor": {
+                    "type": "string"
+                },
+                "detected_at": {
+                    "type": "string"
+                },
+                "detected_descr": {
+                    "type": "string"
+                },
+                "detected_model": {
+                    "type": "string"
+                },
+                "detected_vendor": {
+                    "type": "string"
+                },
+                "host_id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "vendor_mismatch": {
+                    "description": "the configured vendor is still the one used by every task",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.OnuActionRequest": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "description": "disable suspends the service of the onu, reset restores the factory config",
+                    "type": "string",
+                    "enum": [
+                        "reboot",
+                        "disable",
+                        "enable",
+                        "reset"
+                    ]
+                },
+                "oldid": {
+                    "description": "id of estaciones_onu",
+                    "type": "string"
+                },
+                "sn": {
+                    "description": "gpon sn of the onu",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OnuActionResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "host_id": {
+                    "type": "string"
+                },
+                "oldid": {
+                    "type": "string"
+                },
+                "output": {
+                    "type": "string"
+                },
+                "sn": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OnuBandwidth": {
+            "type": "object",
+            "properties": {
+                "applied_at": {
+                    "type": "string"
+                },
+                "down_profile": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "oldid": {
+                    "type": "string"
+                },
+                "up_profile": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OnuBandwidthRequest": {
+            "type": "object",
+            "required": [
+                "oldid",
+                "up_profile"
+            ],
+            "properties": {
+                "down_profile": {
+                    "description": "traffic limit profile of the downstream, empty to remove the limit",
+                    "type": "string"
+                },
+                "oldid": {
+                    "description": "id of estaciones_onu",
+                    "type": "string"
+                },
+                "up_profile": {
+                    "description": "tcont/dba profile of the upstream",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OnuChange": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "delete, move or restore",
+                    "type": "string"
+                },
+                "host_id": {
+                    "type": "string"
+                },
+                "oldid": {
+                    "type": "string"
+                },
+                "onu_id": {
+                    "type": "integer"
+                },
+                "port": {
+                    "type": "string"
+                },
+                "sn": {
+                    "type": "string"
+                },
+                "snmp_index": {
+                    "type": "string"
+                },
+                "to_host_id": {
+                    "type": "string"
+                },
+                "to_onu_id": {
+                    "type": "integer"
+                },
+                "to_port": {
+                    "type": "string"
+                },
+                "to_snmp_index": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OnuDeprovision": {
+            "type": "object",
+            "required": [
+                "host_id",
+                "oldid",
+                "sn"
+            ],
+            "properties": {
+                "host_id": {
+                    "description": "id of the olt on network.host",
+                    "type": "string"
+                },
+                "oldid": {
+                    "description": "id of estaciones_onu",
+                    "type": "string"
+                },
+                "sn": {
+                    "description": "gpon sn of the onu",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OnuMove": {
+            "type": "object",
+            "required": [
+                "from_host_id",
+                "host_id",
+                "name",
+                "oldid",
+                "onu_type",
+                "port",
+                "profile",
+                "sn",
+                "vlan"
+            ],
+            "properties": {
+                "from_host_id": {
+                    "description": "id of the olt where the onu is now",
+                    "type": "string"
+                },
+                "host_id": {
+                    "description": "id of the olt on network.host",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "name of the customer",
+                    "type": "string"
+                },
+                "oldid": {
+                    "description": "id of estaciones_onu",
+                    "type": "string"
+                },
+                "onu_type": {
+                    "description": "type of onu declared on the olt, ZTE-F660",
+                    "type": "string"
+                },
+                "port": {
+                    "description": "pon port as shelf/slot/port, 1/2/1",
+                    "type": "string"
+                },
+                "profile": {
+                    "description": "tcont profile of bandwidth",
+                    "type": "string"
+                },
+                "sn": {
+                    "description": "gpon sn of the onu, ZTEGC8A1B2C3",
+                    "type": "string"
+                },
+                "vlan": {
+                    "description": "vlan of the service",
+                    "type": "integer",
+                    "maximum": 4094,
+                    "minimum": 1
+                }
+            }
+        },
+        "models.OnuProvision": {
+            "type": "object",
+            "required": [
+                "host_id",
+                "name",
+                "oldid",
+                "onu_type",
+                "port",
+                "profile",
+                "sn",
+                "vlan"
+            ],
+            "properties": {
+                "host_id": {
+                    "description": "id of the olt on network.host",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "name of the customer",
+                    "type": "string"
+                },
+                "oldid": {
+                    "description": "id of estaciones_onu",
+                    "type": "string"
+                },
+                "onu_type": {
+                    "description": "type of onu declared on the olt, ZTE-F660",
+                    "type": "string"
+                },
+                "port": {
+                    "description": "pon port as shelf/slot/port, 1/2/1",
+                    "type": "string"
+                },
+                "profile": {
+                    "description": "tcont profile of bandwidth",
+                    "type": "string"
+                },
+                "sn": {
+                    "description": "gpon sn of the onu, ZTEGC8A1B2C3",
+                    "type": "string"
+                },
+                "vlan": {
+                    "description": "vlan of the service",
+                    "type": "integer",
+                    "maximum": 4094,
+                    "minimum": 1
+                }
+            }
+        },
+        "models.OnuProvisioned": {
+            "type": "object",
+            "properties": {
+                "host_id": {
+                    "type": "string"
+                },
+                "interface": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "onu_id": {
+                    "type": "integer"
+                },
+                "port": {
+                    "type": "string"
+                },
+                "sn": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "notice": {
+                    "type": "string"
+                },
+                "record": {}
+            }
+        },
+        "models.UncfgOnu": {
+            "type": "object",
+            "properties": {
+                "first_seen": {
+                    "type": "string"
+                },
+                "host_id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_seen": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "port": {
+                    "type": "string"
+                },
+                "sn": {
+                    "type": "string"
+                }
             }
         }
     },
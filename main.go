@@ -19,6 +19,7 @@ func init() {
 	app.InitDbPgsql()
 	app.InitDbMysql()
 	app.LoadCrontab()
+	app.StartTrapListener()
 
 	gin.SetMode(os.Getenv("GIN_MODE"))
 
@@ -69,6 +70,8 @@ func main() {
 
 	// manual routes
 	controllers.CronRoutes(r)
+	controllers.OltRoutes(r)
+	controllers.OnuRoutes(r)
 
 	// load docs
 	controllers.SwaggerRoutes(r)
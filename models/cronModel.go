@@ -8,7 +8,30 @@ type HostInfo struct {
 	Name           string
 	TelnetUsername string
 	TelnetPasswd   string
-	SnmpCommunity  string
+	CliProtocol    string
+	CliPort        string
+	Ssh            SshOptions
+	Snmp           SnmpAuth
+	Vendor         string
+	Model          string
+	TrafficMode    string // onu_traffic_mode of network.host.info: rate or counters, only zte can use the rates of the olt
+}
+
+// SshOptions are the ssh settings of the olt
+type SshOptions struct {
+	HostKey string // fingerprint pinned for the olt as SHA256:..., if empty the key is checked against the known_hosts file
+	Legacy  bool   // enables the cbc ciphers and the group1 key exchange, only for olts that dont support anything else
+}
+
+// SnmpAuth has the snmp credentials of the olt, if User is set snmpv3 is used and Community is ignored
+type SnmpAuth struct {
+	Community     string
+	User          string
+	AuthProtocol  string
+	AuthPassword  string
+	PrivProtocol  string
+	PrivPassword  string
+	TrapCommunity string // community of the traps v1 and v2c sent by the olt, the read community if it is not set
 }
 
 type ItemResult struct {
@@ -0,0 +1,123 @@
+package models
+
+type OltDetection struct {
+	HostId           string `json:"host_id"`
+	Name             string `json:"name"`
+	Ip               string `json:"ip"`
+	ConfiguredVendor string `json:"configured_vendor"`
+	ConfiguredModel  string `json:"configured_model,omitempty"`
+	DetectedVendor   string `json:"detected_vendor"`
+	DetectedModel    string `json:"detected_model"`
+	DetectedDescr    string `json:"detected_descr"`
+	DetectedAt       string `json:"detected_at"`
+	VendorMismatch   bool   `json:"vendor_mismatch"` // the configured vendor is still the one used by every task
+}
+
+type UncfgOnu struct {
+	HostId    string `json:"host_id"`
+	Name      string `json:"name"`
+	Ip        string `json:"ip"`
+	Port      string `json:"port"`
+	Sn        string `json:"sn"`
+	FirstSeen string `json:"first_seen"`
+	LastSeen  string `json:"last_seen"`
+}
+
+// OnuProvision is the body of POST /onu
+type OnuProvision struct {
+	HostId  string `json:"host_id" binding:"required"`             // id of the olt on network.host
+	Port    string `json:"port" binding:"required"`                // pon port as shelf/slot/port, 1/2/1
+	Sn      string `json:"sn" binding:"required"`                  // gpon sn of the onu, ZTEGC8A1B2C3
+	OnuType string `json:"onu_type" binding:"required"`            // type of onu declared on the olt, ZTE-F660
+	Profile string `json:"profile" binding:"required"`             // tcont profile of bandwidth
+	Vlan    int    `json:"vlan" binding:"required,min=1,max=4094"` // vlan of the service
+	Name    string `json:"name" binding:"required"`                // name of the customer
+	OldId   string `json:"oldid" binding:"required,numeric"`       // id of estaciones_onu
+}
+
+type OnuProvisioned struct {
+	HostId    string `json:"host_id"`
+	Port      string `json:"port"`
+	OnuId     int    `json:"onu_id"`
+	Interface string `json:"interface"`
+	Name      string `json:"name"`
+	Sn        string `json:"sn"`
+}
+
+// OnuDeprovision is the body of DELETE /onu
+type OnuDeprovision struct {
+	HostId string `json:"host_id" binding:"required"`       // id of the olt on network.host
+	Sn     string `json:"sn" binding:"required"`            // gpon sn of the onu
+	OldId  string `json:"oldid" binding:"required,numeric"` // id of estaciones_onu
+}
+
+// OnuMove is the body of POST /onu/move, the onu is removed from from_host_id and provisioned as the rest of the fields
+type OnuMove struct {
+	FromHostId string `json:"from_host_id" binding:"required"` // id of the olt where the onu is now
+	OnuProvision
+}
+
+// OnuChange is the record of network.onu_change left by a delete or a move of an onu, restore when a failed move put the onu back
+type OnuChange struct {
+	Action      string `json:"action"` // delete, move or restore
+	OldId       string `json:"oldid"`
+	Sn          string `json:"sn"`
+	HostId      string `json:"host_id"`
+	Port        string `json:"port"`
+	OnuId       int    `json:"onu_id"`
+	SnmpIndex   string `json:"snmp_index"`
+	ToHostId    string `json:"to_host_id,omitempty"`
+	ToPort      string `json:"to_port,omitempty"`
+	ToOnuId     int    `json:"to_onu_id,omitempty"`
+	ToSnmpIndex string `json:"to_snmp_index,omitempty"`
+}
+
+// OnuActionRequest is the body of POST /onu/action, the onu is found by oldid or by sn
+type OnuActionRequest struct {
+	OldId  string `json:"oldid" binding:"omitempty,numeric"`                           // id of estaciones_onu
+	Sn     string `json:"sn"`                                                          // gpon sn of the onu
+	Action string `json:"action" binding:"required,oneof=reboot disable enable reset"` // disable suspends the service of the onu, reset restores the factory config
+}
+
+// OnuActionResult is the outcome of the action, it is also recorded on network.onu_action
+type OnuActionResult struct {
+	HostId string `json:"host_id"`
+	OldId  string `json:"oldid"`
+	Sn     string `json:"sn"`
+	Action string `json:"action"`
+	Output string `json:"output"`
+}
+
+// OnuBandwidthRequest is the body of PUT /onu/bandwidth, the desired profiles of bandwidth of the onu of the customer
+type OnuBandwidthRequest struct {
+	OldId       string `json:"oldid" binding:"required,numeric"` // id of estaciones_onu
+	UpProfile   string `json:"up_profile" binding:"required"`    // tcont/dba profile of the upstream
+	DownProfile string `json:"down_profile"`                     // traffic limit profile of the downstream, empty to remove the limit
+}
+
+// OnuBandwidth is the desired state of network.onu_bandwidth and when it was applied on the olt
+type OnuBandwidth struct {
+	OldId       string `json:"oldid"`
+	UpProfile   string `json:"up_profile"`
+	DownProfile string `json:"down_profile"`
+	UpdatedAt   string `json:"updated_at"`
+	AppliedAt   string `json:"applied_at"`
+	LastError   string `json:"last_error"`
+}
+
+// CliRender is the body of POST /olt/cli-render, the vendor and model are the ones of host_id when it is set
+type CliRender struct {
+	HostId   string         `json:"host_id"`                     // id of the olt on network.host
+	Vendor   string         `json:"vendor"`                      // zte, vsol or cdata
+	Model    string         `json:"model"`                       // model with its own templates, empty for the generic ones
+	Template string         `json:"template" binding:"required"` // name of the template, onu_register
+	Data     map[string]any `json:"data"`                        // keys used by the template, {"port":"1/2/1","onu_id":7}
+}
+
+// CliRendered are the commands that would be sent to the olt
+type CliRendered struct {
+	Vendor   string   `json:"vendor"`
+	Model    string   `json:"model"`
+	Template string   `json:"template"`
+	Commands []string `json:"commands"`
+}
@@ -0,0 +1,34 @@
+package repo
+
+import (
+	"fmt"
+
+	"ired.com/olt/clitemplate"
+	"ired.com/olt/models"
+)
+
+// RenderCliTemplate renders the template without connecting to the olt, to check the commands of a batch before running it
+func RenderCliTemplate(db models.ConnDb, request models.CliRender) (models.CliRendered, error) {
+	if request.HostId != "" {
+		host, err := getOltHost(db.Ctx, db.Conn, request.HostId)
+		if err != nil {
+			return models.CliRendered{}, fmt.Errorf("error getting olt: %w", err)
+		}
+		request.Vendor, request.Model = host.Vendor, host.Model
+	}
+	if request.Vendor == "" {
+		return models.CliRendered{}, fmt.Errorf("host_id or vendor is required")
+	}
+
+	commands, err := clitemplate.Render(request.Vendor, request.Model, request.Template, request.Data)
+	if err != nil {
+		return models.CliRendered{}, err
+	}
+
+	return models.CliRendered{Vendor: request.Vendor, Model: request.Model, Template: request.Template, Commands: commands}, nil
+}
+
+// GetCliTemplates returns the names of the templates of the vendor
+func GetCliTemplates(vendor string) []string {
+	return clitemplate.Names(vendor)
+}
@@ -48,10 +48,11 @@ func CleanOnuData(db models.ConnDb, caller string) error {
 	}
 	utils.Logline("Rows affected delete host_item where nombre is onu-tx2", commandTag.RowsAffected())
 
-	//check if items with name onu-(sn|name|rx|status) are duplicated, and remove the ones that dont have data in the last 12hours
+	//check if items with name onu-(sn|name|rx|status|rxonu|temperature|voltage|bias|distance|lastonline|lastoffline|downcause) are duplicated, and remove the ones that dont have data in the last 12hours
+	//the items of the onus removed by the api (sn removed-<index>) keep the history of the customer and are never cleaned
 	query = `SELECT hi.oldid, hi.nombre
 		FROM network.host_item as hi
-		WHERE hi.nombre SIMILAR TO 'onu-(sn|name|rx|status)' AND hi.oldid IS NOT NULL
+		WHERE hi.nombre SIMILAR TO 'onu-(sn|name|rx|status|rxonu|temperature|voltage|bias|distance|lastonline|lastoffline|downcause)' AND hi.oldid IS NOT NULL AND hi.sn NOT LIKE 'removed-%'
 		GROUP BY hi.oldid, hi.nombre
 		HAVING COUNT(*)>1`
 	rows1, err := db.Conn.Query(db.Ctx, query)
@@ -78,7 +79,7 @@ func CleanOnuData(db models.ConnDb, caller string) error {
 	//check if items with name onu-(ethlist|tx) are duplicated, and remove the ones that dont have data in the last 12hours
 	query = `SELECT hi.oldid, hi.nombre
 		FROM network.host_item as hi
-		WHERE hi.nombre SIMILAR TO 'onu-(ethlist|tx)' AND hi.oldid IS NOT NULL
+		WHERE hi.nombre SIMILAR TO 'onu-(ethlist|tx)' AND hi.oldid IS NOT NULL AND hi.sn NOT LIKE 'removed-%'
 		GROUP BY hi.oldid, hi.nombre
 		HAVING COUNT(*)>1`
 	rows2, err := db.Conn.Query(db.Ctx, query)
@@ -111,7 +112,7 @@ func CleanOnuData(db models.ConnDb, caller string) error {
 func validItemOnu(db models.ConnDb, oldId string, itemName string) error {
 	//this query looks if the items have recieve some value in the last 12h and also checks if hostId is active in the last 24h
 	query := `WITH items AS (
-			SELECT id, host_id FROM network.host_item WHERE oldid=$1 AND nombre=$2 ORDER BY id ASC
+			SELECT id, host_id FROM network.host_item WHERE oldid=$1 AND nombre=$2 AND sn NOT LIKE 'removed-%' ORDER BY id ASC
 		), host_status AS (
 			SELECT q0.host_id, 
 				CASE
@@ -188,7 +189,7 @@ func validItemOnu(db models.ConnDb, oldId string, itemName string) error {
 
 func validItemOnu2(db models.ConnDb, oldId string, itemName string) error {
 	//this query looks if onu-status exist for that specific sn, keep in mind that onu-ethlist and onu-tx dont store value often
-	query := `SELECT sn FROM network.host_item WHERE oldid=$1 GROUP BY sn HAVING COUNT(*)<=2`
+	query := `SELECT sn FROM network.host_item WHERE oldid=$1 AND sn NOT LIKE 'removed-%' GROUP BY sn HAVING COUNT(*)<=2`
 	rows1, err := db.Conn.Query(db.Ctx, query, oldId)
 	if err != nil {
 		return fmt.Errorf("error on query items validity with Oldid(%s) and ItenName(%s) validity: %w", oldId, itemName, err)
@@ -3,6 +3,7 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -16,41 +17,17 @@ func OltAutoWrite(db models.ConnMysqlPgsql, caller string) error {
 	utils.Logline(utils.ShowStatusWorkerMysql(db, "oltAutoWrite", caller+"/begin"))
 
 	//get olts to work on
-	query := `SELECT h.id, h.ip, h.info->>'telnet_username' as username, h.info->>'telnet_password' as password, h.info->>'snmp_read_community' as community
-		FROM network.host as h
-		WHERE h.info->>'telnet_username' IS NOT NULL AND h.info->>'snmp_read_community' IS NOT NULL AND h.activo=true
-		ORDER BY RANDOM()`
-	rows, err := db.ConnPgsql.Query(db.Ctx, query)
+	hostsInfo, err := getOltHosts(db.Ctx, db.ConnPgsql)
 	if err != nil {
 		utils.Logline("error getting host to run cron", err)
 		return err
 	}
-	defer rows.Close()
-
-	//create slice of hosts
-	var hostsInfo []models.HostInfo
-	for rows.Next() {
-		var host models.HostInfo
-		err = rows.Scan(&host.Id, &host.Ip, &host.TelnetUsername, &host.TelnetPasswd, &host.SnmpCommunity)
-		if err != nil {
-			utils.Logline("error scanning rows of host olts", err)
-			return err
-		}
-		hostsInfo = append(hostsInfo, host)
-	}
-	rows.Close()
 
 	//iterate over hosts and create one goroutine for every olt
 	var wg sync.WaitGroup
 	for _, host := range hostsInfo {
 		wg.Add(1)
-		go func() {
-			if host.TelnetUsername != "vsol" && host.TelnetUsername != "cdata" {
-				workerZteAutoWrite(&wg, db, host)
-			} else {
-				wg.Done()
-			}
-		}()
+		go workerAutoWrite(&wg, db, host)
 	}
 
 	wg.Wait()
@@ -61,17 +38,23 @@ func OltAutoWrite(db models.ConnMysqlPgsql, caller string) error {
 	return nil
 }
 
-func workerZteAutoWrite(wg *sync.WaitGroup, db models.ConnMysqlPgsql, host models.HostInfo) {
+func workerAutoWrite(wg *sync.WaitGroup, db models.ConnMysqlPgsql, host models.HostInfo) {
 	defer wg.Done()
 
 	defer func() {
 		// recover from panic if one occured. Set err to nil otherwise.
 		if recover() != nil {
-			utils.Logline("error on this subprocess - workerZteClock", host.Ip.String())
+			utils.Logline("error on this subprocess - workerAutoWrite", host.Ip.String())
 			return
 		}
 	}()
 
+	driver, err := getDriver(host.Vendor)
+	if err != nil {
+		utils.Logline("error getting driver", host.Ip.String(), err)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
 
@@ -102,20 +85,15 @@ func workerZteAutoWrite(wg *sync.WaitGroup, db models.ConnMysqlPgsql, host model
 		return
 	}
 
-	// Connect to the OLT
-	conn, err := utils.OltZteConnect(host.Ip.String(), "23", host.TelnetUsername, host.TelnetPasswd)
-	if err != nil {
-		utils.Logline("Couldnt establish connection", err)
-		return
-	}
-	defer conn.Close()
-
-	//send command and read response
-	if _, err = utils.OltZteSend(conn, "write", "#", 55*time.Second); err != nil {
-		utils.Logline("error proccesing 'write'", host.Ip.String(), err)
+	//save config on the olt
+	if err = driver.SaveConfig(host); err != nil {
+		if errors.Is(err, errDriverUnsupported) {
+			utils.Logline(host.Vendor+" worker on construction", host.Ip.String())
+			return
+		}
+		utils.Logline("error saving config", host.Ip.String(), err)
 		return
 	}
-	conn.Close()
 
 	ctx, cancel = context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
@@ -0,0 +1,244 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	g "github.com/gosnmp/gosnmp"
+	"ired.com/olt/models"
+	"ired.com/olt/utils"
+)
+
+type oltDetected struct {
+	Vendor string
+	Model  string
+	Descr  string
+}
+
+// enterprise number of sysObjectID for every vendor supported
+var enterpriseVendors = map[string]string{
+	"3902":  "zte",
+	"37950": "vsol",
+	"17409": "cdata",
+}
+
+var (
+	reModelZte   = regexp.MustCompile(`c[3-6]\d\d`)
+	reModelVsol  = regexp.MustCompile(`v\d{4}[a-z0-9]*`)
+	reModelCdata = regexp.MustCompile(`fd\d{4}[a-z0-9-]*`)
+)
+
+func OltDiscovery(db models.ConnDb, caller string) error {
+	//show status of worker
+	utils.Logline(utils.ShowStatusWorker(db, "oltDiscovery", caller+"/begin"))
+
+	//get olts to work on
+	query := `SELECT h.id, h.ip, h.nombre, ` + hostSnmpSql + `, ` + hostVendorSql + ` as vendor, COALESCE(h.info->>'model', '') as model
+		FROM network.host as h
+		WHERE h.info->>'telnet_username' IS NOT NULL AND ` + hostHasSnmpSql + ` AND h.activo=true
+		ORDER BY RANDOM()`
+	rows, err := db.Conn.Query(db.Ctx, query)
+	if err != nil {
+		utils.Logline("error getting host to run cron", err)
+		return err
+	}
+	defer rows.Close()
+
+	//create slice of hosts, vendor and model are the ones configured by hand
+	var hostsInfo []models.HostInfo
+	for rows.Next() {
+		var host models.HostInfo
+		err = rows.Scan(&host.Id, &host.Ip, &host.Name,
+			&host.Snmp.Community, &host.Snmp.User, &host.Snmp.AuthProtocol, &host.Snmp.AuthPassword, &host.Snmp.PrivProtocol, &host.Snmp.PrivPassword, &host.Snmp.TrapCommunity,
+			&host.Vendor, &host.Model)
+		if err != nil {
+			utils.Logline("error scanning rows of host olts", err)
+			return err
+		}
+		hostsInfo = append(hostsInfo, host)
+	}
+	rows.Close()
+
+	//iterate over hosts and create one goroutine for every olt
+	var wg sync.WaitGroup
+	for _, host := range hostsInfo {
+		wg.Add(1)
+		go workerOltDiscovery(&wg, db, host)
+	}
+
+	wg.Wait()
+
+	//show status of worker
+	utils.Logline(utils.ShowStatusWorker(db, "oltDiscovery", caller+"/ending"))
+
+	return nil
+}
+
+func workerOltDiscovery(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo) {
+	defer wg.Done()
+
+	defer func() {
+		// recover from panic if one occured. Set err to nil otherwise.
+		if recover() != nil {
+			utils.Logline("error on this subprocess - workerOltDiscovery", host.Ip.String())
+			return
+		}
+	}()
+
+	detected, err := detectOlt(host)
+	if err != nil {
+		utils.Logline("error detecting vendor and model", host.Ip.String(), host.Name, err)
+		return
+	}
+
+	//log if the detection disagrees with the data configured by hand
+	if detected.Vendor != host.Vendor || (host.Model != "" && detected.Model != host.Model) {
+		utils.Logline(fmt.Sprintf("detected olt (%s %s) differs from configured (%s %s)", detected.Vendor, detected.Model, host.Vendor, host.Model), host.Ip.String(), host.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	query := `UPDATE network.host
+		SET info = info || jsonb_build_object('detected_vendor', $2::text, 'detected_model', $3::text, 'detected_descr', $4::text, 'detected_at', NOW())
+		WHERE id=$1`
+	if _, err := db.Conn.Exec(ctx, query, host.Id, detected.Vendor, detected.Model, detected.Descr); err != nil {
+		utils.Logline("error updating network.host with detected vendor", host.Ip.String(), host.Name, err)
+		return
+	}
+}
+
+// detectOlt reads sysObjectID.0 and sysDescr.0 to find out vendor and model of the olt
+func detectOlt(host models.HostInfo) (oltDetected, error) {
+	var detected oltDetected
+
+	//connect to snmp
+	connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.Snmp, 4, 4, true)
+	if err != nil {
+		return detected, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer connSnmp.Conn.Close()
+
+	oids := []string{
+		".1.3.6.1.2.1.1.2.0", //sysObjectID
+		".1.3.6.1.2.1.1.1.0", //sysDescr
+	}
+	resultSnmp, err := connSnmp.Get(oids)
+	if err != nil {
+		return detected, fmt.Errorf("error performing Get for OIDs %v: %w", oids, err)
+	}
+
+	var sysObjectId string
+	for _, value := range resultSnmp.Variables {
+		switch value.Name {
+		case ".1.3.6.1.2.1.1.2.0":
+			sysObjectId = fmt.Sprintf("%s", value.Value)
+		case ".1.3.6.1.2.1.1.1.0":
+			detected.Descr = strings.TrimSpace(fmt.Sprintf("%s", value.Value))
+		}
+	}
+
+	detected.Vendor, detected.Model = vendorModelFromSysInfo(sysObjectId, detected.Descr)
+	if detected.Vendor == "" {
+		return detected, fmt.Errorf("unknown vendor for sysObjectID (%s) and sysDescr (%s)", sysObjectId, detected.Descr)
+	}
+
+	// sysDescr of the c300 mini says c300, se determina en relacion al tipo de tarjetas
+	if detected.Vendor == "zte" && detected.Model == "c300" {
+		oid := ".1.3.6.1.4.1.3902.1082.10.1.2.4.1.4" //boardType
+		resultSnmp, err := connSnmp.BulkWalkAll(oid)
+		if err != nil {
+			return detected, fmt.Errorf("error performing BulkWalk on %s: %w", oid, err)
+		}
+		detected.Model = modelZteFromCards(resultSnmp)
+	}
+
+	return detected, nil
+}
+
+// vendorModelFromSysInfo maps the enterprise number of sysObjectID and the text of sysDescr to vendor and model
+func vendorModelFromSysInfo(sysObjectId string, sysDescr string) (string, string) {
+	var vendor, model string
+
+	//sysObjectID has the form .1.3.6.1.4.1.<enterprise>.x.y
+	parts := strings.Split(strings.TrimPrefix(strings.TrimPrefix(sysObjectId, "."), "1.3.6.1.4.1."), ".")
+	if len(parts) > 0 {
+		vendor = enterpriseVendors[parts[0]]
+	}
+
+	descr := strings.ToLower(sysDescr)
+	if vendor == "" {
+		switch {
+		case strings.Contains(descr, "zte") || strings.Contains(descr, "zxa10"):
+			vendor = "zte"
+		case strings.Contains(descr, "vsol") || strings.Contains(descr, "v-sol"):
+			vendor = "vsol"
+		case strings.Contains(descr, "cdata") || strings.Contains(descr, "c-data"):
+			vendor = "cdata"
+		}
+	}
+
+	switch vendor {
+	case "zte":
+		model = reModelZte.FindString(descr)
+	case "vsol":
+		model = reModelVsol.FindString(descr)
+	case "cdata":
+		model = reModelCdata.FindString(descr)
+	}
+
+	return vendor, model
+}
+
+// modelZteFromCards returns c300 mini when the olt has scxn or scxm cards and no prwg card
+func modelZteFromCards(cards []g.SnmpPDU) string {
+	modelo := "c300"
+	for _, value := range cards {
+		cardValue := strings.TrimSpace(strings.ToLower(fmt.Sprintf("%s", value.Value)))
+		if cardValue == "prwg" {
+			return "c300"
+		} else if cardValue == "scxn" || cardValue == "scxm" {
+			modelo = "c300 mini"
+		}
+	}
+	return modelo
+}
+
+// GetOltDetections returns the olts where the detected vendor or model disagrees with the configured data
+func GetOltDetections(db models.ConnDb) ([]models.OltDetection, error) {
+	query := `SELECT h.id, h.nombre, host(h.ip), ` + hostVendorSql + ` as configured_vendor, h.info->>'model' as configured_model,
+			h.info->>'detected_vendor', COALESCE(h.info->>'detected_model', ''), COALESCE(h.info->>'detected_descr', ''), COALESCE(h.info->>'detected_at', '')
+		FROM network.host as h
+		WHERE h.info->>'detected_vendor' IS NOT NULL AND h.activo=true
+			AND (h.info->>'detected_vendor'<>` + hostVendorSql + ` OR h.info->>'detected_model'<>h.info->>'model')
+		ORDER BY h.ip ASC`
+	rows, err := db.Conn.Query(db.Ctx, query)
+	if err != nil {
+		utils.Logline("error getting olt detections", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	detections := []models.OltDetection{}
+	for rows.Next() {
+		var detection models.OltDetection
+		var configuredModel sql.NullString
+		err = rows.Scan(&detection.HostId, &detection.Name, &detection.Ip, &detection.ConfiguredVendor, &configuredModel,
+			&detection.DetectedVendor, &detection.DetectedModel, &detection.DetectedDescr, &detection.DetectedAt)
+		if err != nil {
+			utils.Logline("error scanning rows of olt detections", err)
+			return nil, err
+		}
+		detection.ConfiguredModel = configuredModel.String
+		detection.VendorMismatch = detection.DetectedVendor != detection.ConfiguredVendor
+		detections = append(detections, detection)
+	}
+	rows.Close()
+
+	return detections, nil
+}
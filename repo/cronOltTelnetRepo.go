@@ -14,12 +14,8 @@ import (
 )
 
 type oltInfo struct {
-	Id       string
-	Nombre   string
-	Ip       netip.Addr
-	Username string
-	Passwd   string
-	ItemId   sql.NullString
+	models.HostInfo
+	ItemId sql.NullString
 }
 
 type clockResult struct {
@@ -32,7 +28,8 @@ func GetClock(db models.ConnDb, caller string) error {
 	//show status of worker
 	utils.Logline(utils.ShowStatusWorker(db, "getClock", caller+"/begin"))
 
-	query := `SELECT h.id, h.nombre, h.ip, h.info->>'telnet_username' as username, h.info->>'telnet_password' as passwd, hi.id as item_id
+	query := `SELECT h.id, h.nombre, h.ip, h.info->>'telnet_username' as username, h.info->>'telnet_password' as passwd, hi.id as item_id, ` + hostVendorSql + ` as vendor,
+			` + hostCliProtocolSql + ` as cli_protocol, COALESCE(h.info->>'cli_port', '') as cli_port
 		FROM network.host as h
 		LEFT JOIN network.host_item as hi ON hi.host_id=h.id AND hi.nombre='olt-clock'
 		WHERE h.info->>'telnet_password' IS NOT NULL AND h.info->>'telnet_username' IS NOT NULL AND h.activo=true
@@ -49,7 +46,7 @@ func GetClock(db models.ConnDb, caller string) error {
 	var hostsData []oltInfo
 	for rows.Next() {
 		var host oltInfo
-		err = rows.Scan(&host.Id, &host.Nombre, &host.Ip, &host.Username, &host.Passwd, &host.ItemId)
+		err = rows.Scan(&host.Id, &host.Name, &host.Ip, &host.TelnetUsername, &host.TelnetPasswd, &host.ItemId, &host.Vendor, &host.CliProtocol, &host.CliPort)
 		if err != nil {
 			utils.Logline("error scanning rows of host olts", err)
 			return err
@@ -62,13 +59,7 @@ func GetClock(db models.ConnDb, caller string) error {
 	results := make(chan clockResult, 20)
 	for _, host := range hostsData {
 		wg.Add(1)
-		if host.Username == "vsol" {
-			go workerVsolClock(&wg, db, host.Ip, hostsData, results)
-		} else if host.Username == "cdata" {
-			go workerCdataClock(&wg, db, host.Ip, hostsData, results)
-		} else {
-			go workerZteClock(&wg, db, host.Ip, hostsData, results)
-		}
+		go workerClock(&wg, db, host.Ip, hostsData, results)
 	}
 
 	go func() {
@@ -87,13 +78,13 @@ func GetClock(db models.ConnDb, caller string) error {
 	return nil
 }
 
-func workerZteClock(wg *sync.WaitGroup, db models.ConnDb, hostIp netip.Addr, hosts []oltInfo, results chan<- clockResult) {
+func workerClock(wg *sync.WaitGroup, db models.ConnDb, hostIp netip.Addr, hosts []oltInfo, results chan<- clockResult) {
 	defer wg.Done()
 
 	defer func() {
 		// recover from panic if one occured. Set err to nil otherwise.
 		if recover() != nil {
-			utils.Logline("error on this subprocess - workerZteClock", hostIp.String())
+			utils.Logline("error on this subprocess - workerClock", hostIp.String())
 			return
 		}
 	}()
@@ -104,37 +95,16 @@ func workerZteClock(wg *sync.WaitGroup, db models.ConnDb, hostIp netip.Addr, hos
 		return
 	}
 
-	// Connect to the OLT
-	conn, err := utils.OltZteConnect(hostInfo.Ip.String(), "23", hostInfo.Username, hostInfo.Passwd)
+	driver, err := getDriver(hostInfo.Vendor)
 	if err != nil {
-		utils.Logline("Couldnt establish connection", err)
-		return
-	}
-	defer conn.Close()
-
-	//send command and read response
-	var response string
-	if response, err = utils.OltZteSend(conn, "show clock", "#", 2*time.Second); err != nil {
-		utils.Logline("error proccesing 'show clock'", err)
+		utils.Logline("error getting driver", hostIp.String(), err)
 		return
 	}
-	conn.Close()
 
-	//clean response and store it
-	dateOlt := cleanZteDate(response)
-
-	// Parse the string into a time.Time object
-	loc, err := time.LoadLocation("America/Caracas")
+	//get date from the olt
+	dateOlt, t, err := driver.Clock(hostInfo.HostInfo)
 	if err != nil {
-		utils.Logline("Error loading location:", hostInfo.Ip.String(), err)
-		return
-	}
-
-	// Define the layout for parsing
-	layout := "15:04:05 Mon Jan 02 2006"
-	t, err := time.ParseInLocation(layout, dateOlt[:24], loc)
-	if err != nil {
-		utils.Logline("Error parsing date:", hostInfo.Ip.String(), err)
+		utils.Logline("error getting clock", hostIp.String(), err)
 		return
 	}
 
@@ -153,148 +123,6 @@ func workerZteClock(wg *sync.WaitGroup, db models.ConnDb, hostIp netip.Addr, hos
 	}
 }
 
-func workerVsolClock(wg *sync.WaitGroup, db models.ConnDb, hostIp netip.Addr, hosts []oltInfo, results chan<- clockResult) {
-	defer wg.Done()
-
-	defer func() {
-		// recover from panic if one occured. Set err to nil otherwise.
-		if recover() != nil {
-			utils.Logline("error on this subprocess - workerVsolClock", hostIp.String())
-			return
-		}
-	}()
-
-	hostInfo := findOltInfoByIp(hosts, hostIp.String())
-	if hostInfo == nil {
-		utils.Logline("error finding host info for ip", hostIp.String())
-		return
-	}
-
-	// Connect to the OLT
-	conn, err := utils.OltVsolConnect(hostInfo.Ip.String(), "23", hostInfo.Username, hostInfo.Passwd)
-	if err != nil {
-		utils.Logline("Couldnt establish connection", err)
-		return
-	}
-	defer conn.Close()
-
-	//send command and read response
-	var response string
-	if response, err = utils.OltZteSend(conn, "show time", "#", 2*time.Second); err != nil {
-		utils.Logline("error proccesing 'show clock'", err)
-		return
-	}
-	conn.Close()
-
-	//clean response and parse it
-	response = cleanZteDate(response)
-	for _, line := range strings.Split(response, "\n") {
-		if strings.Contains(line, "current date") {
-			response = strings.TrimSpace(strings.ReplaceAll(strings.ToLower(line), "the current date/time is :", ""))
-		}
-	}
-
-	// Parse the string into a time.Time object
-	loc, err := time.LoadLocation("America/Caracas")
-	if err != nil {
-		utils.Logline("Error loading location:", hostIp.String(), err)
-		return
-	}
-
-	// Define the layout for parsing
-	layout := "Mon Jan 02 15:04:05 2006"
-	t, err := time.ParseInLocation(layout, response, loc)
-	if err != nil {
-		utils.Logline("Error parsing date:", hostIp.String(), err)
-		return
-	}
-
-	//create json string to save it in DB
-	horaPgsql := fmt.Sprintf(`{"olt_format": "%s", "timestamptz_servidor": "%s", "timestamptz_olt": "%s"}`, response, time.Now().Local().Format(time.RFC3339), t.Format(time.RFC3339))
-
-	//validar si itemId existe sino crearlo
-	itemId := hostInfo.ItemId.String
-	if !hostInfo.ItemId.Valid {
-		itemId = createItem(db, hostInfo.Id, hostInfo.Ip, "telnet-show-clock", "olt-clock")
-	}
-
-	// si itemId es correcto concatenar para insertar
-	if itemId != "" {
-		results <- clockResult{ItemId: itemId, Value: horaPgsql}
-	}
-}
-
-func workerCdataClock(wg *sync.WaitGroup, db models.ConnDb, hostIp netip.Addr, hosts []oltInfo, results chan<- clockResult) {
-	defer wg.Done()
-
-	defer func() {
-		// recover from panic if one occured. Set err to nil otherwise.
-		if recover() != nil {
-			utils.Logline("error on this subprocess - workerCdataClock", hostIp.String())
-			return
-		}
-	}()
-
-	hostInfo := findOltInfoByIp(hosts, hostIp.String())
-	if hostInfo == nil {
-		utils.Logline("error finding host info for ip", hostIp.String())
-		return
-	}
-
-	// Connect to the OLT
-	conn, err := utils.OltCdataConnect(hostInfo.Ip.String(), "23", hostInfo.Username, hostInfo.Passwd)
-	if err != nil {
-		utils.Logline("Couldnt establish connection", err)
-		return
-	}
-	defer conn.Close()
-
-	//send command and read response
-	var response string
-	if response, err = utils.OltZteSend(conn, "show time", "#", 2*time.Second); err != nil {
-		utils.Logline("error proccesing 'show clock'", err)
-		return
-	}
-	conn.Close()
-
-	//clean response and parse it
-	response = strings.TrimSpace(utils.OltRemoveLastLine(response))
-	for _, line := range strings.Split(response, "\n") {
-		if !strings.Contains(line, "show time") {
-			response = strings.TrimSpace(line)
-		}
-	}
-
-	// Parse the string into a time.Time object
-	loc, err := time.LoadLocation("America/Caracas")
-	if err != nil {
-		utils.Logline("Error loading location:", hostIp.String(), err)
-		return
-	}
-
-	// Define the layout for parsing
-	layout := "2006-01-02 15:04:05"
-	t, err := time.ParseInLocation(layout, response[:19], loc)
-	if err != nil {
-		utils.Logline("Error parsing date:", hostIp.String(), err)
-		return
-	}
-
-	//create json string to save it in DB
-	horaPgsql := fmt.Sprintf(`{"olt_format": "%s", "timestamptz_servidor": "%s", "timestamptz_olt": "%s"}`, response, time.Now().Local().Format(time.RFC3339), t.Format(time.RFC3339))
-
-	//validar si itemId existe sino crearlo
-	itemId := hostInfo.ItemId.String
-	if !hostInfo.ItemId.Valid {
-		itemId = createItem(db, hostInfo.Id, hostInfo.Ip, "telnet-show-clock", "olt-clock")
-	}
-
-	// si itemId es correcto concatenar para insertar
-	if itemId != "" {
-		results <- clockResult{ItemId: itemId, Value: horaPgsql}
-	}
-}
-
 func createItem(db models.ConnDb, hostId string, hostIp netip.Addr, itemSn string, itemName string) (itemId string) {
 	query := `WITH ins AS (
 			INSERT INTO network.host_item (empresa_id, activo, host_id, sn, ip, nombre)
@@ -372,7 +200,7 @@ func findOltInfoByIp(hosts []oltInfo, targetIp string) *oltInfo {
 }
 
 func cleanZteDate(s string) string {
-	dateOlt := strings.TrimSpace(utils.OltRemoveLastLine(s))
+	dateOlt := utils.OltCleanOutput(s)
 	dateOlt = strings.ReplaceAll(dateOlt, " 1 ", " 01 ")
 	dateOlt = strings.ReplaceAll(dateOlt, " 2 ", " 02 ")
 	dateOlt = strings.ReplaceAll(dateOlt, " 3 ", " 03 ")
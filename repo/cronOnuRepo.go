@@ -3,7 +3,10 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -13,6 +16,59 @@ import (
 	"ired.com/olt/utils"
 )
 
+// names of network.host_item of every onu
+var onuItemNames = map[string]string{
+	"itemSn":             "onu-sn",
+	"itemEthlist":        "onu-ethlist",
+	"itemOnuName":        "onu-name",
+	"itemOnuTx":          "onu-tx",
+	"itemOnuRx":          "onu-rx",
+	"itemOnuStatus":      "onu-status",
+	"itemOnuRxOnu":       "onu-rxonu",
+	"itemOnuTemperature": "onu-temperature",
+	"itemOnuVoltage":     "onu-voltage",
+	"itemOnuBias":        "onu-bias",
+	"itemOnuDistance":    "onu-distance",
+	"itemOnuLastOnline":  "onu-lastonline",
+	"itemOnuLastOffline": "onu-lastoffline",
+	"itemOnuDownCause":   "onu-downcause",
+}
+
+// items created for every onu after onu-sn
+var onuItemsCreated = []string{"itemEthlist", "itemOnuName", "itemOnuRx", "itemOnuStatus"}
+
+// items of the onu read from metrics of the catalog that not every vendor has, they are created and read
+// only if the metric exists for the olt, every n minutes starting on minute offset
+var onuExtraItems = []struct {
+	metric string
+	item   string
+	table  string
+	every  int
+	offset int
+}{
+	{"onu-tx", "itemOnuTx", "detalle_int", 15, 3},
+	{"onu-rxonu", "itemOnuRxOnu", "detalle_int", 15, 3},
+	{"onu-temperature", "itemOnuTemperature", "detalle_int", 15, 3},
+	{"onu-voltage", "itemOnuVoltage", "detalle_int", 15, 3},
+	{"onu-bias", "itemOnuBias", "detalle_int", 15, 3},
+	{"onu-distance", "itemOnuDistance", "detalle_int", 30, 4},
+	{"onu-lastonline", "itemOnuLastOnline", "detalle_text", 5, 2},
+	{"onu-lastoffline", "itemOnuLastOffline", "detalle_text", 5, 2},
+	{"onu-downcause", "itemOnuDownCause", "detalle_text", 5, 2},
+}
+
+// columns of the table of ethernet ports of the onus, indexed by snmpIndex of the onu and number of port
+var onuEthMetrics = []string{"onu-eth-admin", "onu-eth-link", "onu-eth-speed", "onu-eth-duplex"}
+
+// state of one ethernet port of the onu as stored on onu-ethlist
+type onuEthPort struct {
+	Port   int    `json:"port"`
+	Admin  string `json:"admin"`
+	Link   string `json:"link"`
+	Speed  string `json:"speed"`
+	Duplex string `json:"duplex"`
+}
+
 type itemsCronOnu struct {
 	itemId     string
 	itemSn     sql.NullString
@@ -26,45 +82,17 @@ func CronOnuInfo(db models.ConnDb, caller string) error {
 	utils.Logline(utils.ShowStatusWorker(db, "onuInfo", caller+"/begin"))
 
 	//get olts to work on
-	query := `SELECT h.id, h.ip, h.nombre, h.info->>'telnet_username' as username, h.info->>'telnet_password' as password, h.info->>'snmp_read_community' as community
-		FROM network.host as h
-		WHERE h.info->>'telnet_username' IS NOT NULL AND h.info->>'snmp_read_community' IS NOT NULL AND h.activo=true
-		ORDER BY RANDOM()`
-	rows, err := db.Conn.Query(db.Ctx, query)
+	hostsInfo, err := getOltHosts(db.Ctx, db.Conn)
 	if err != nil {
 		utils.Logline("error getting host to run cron", err)
 		return err
 	}
-	defer rows.Close()
-
-	//create slice of hosts
-	var hostsInfo []models.HostInfo
-	for rows.Next() {
-		var host models.HostInfo
-		err = rows.Scan(&host.Id, &host.Ip, &host.Name, &host.TelnetUsername, &host.TelnetPasswd, &host.SnmpCommunity)
-		if err != nil {
-			utils.Logline("error scanning rows of host olts", err)
-			return err
-		}
-		hostsInfo = append(hostsInfo, host)
-	}
-	rows.Close()
 
 	//iterate over hosts and create one goroutine for every olt
 	var wg sync.WaitGroup
 	for _, host := range hostsInfo {
 		wg.Add(1)
-		go func() {
-			if host.TelnetUsername == "vsol" {
-				utils.Logline("vsol worker on construction", host.Ip.String())
-				wg.Done()
-			} else if host.TelnetUsername == "cdata" {
-				utils.Logline("cdata worker on construction", host.Ip.String())
-				wg.Done()
-			} else {
-				workerZteOnuInfo(&wg, db, host)
-			}
-		}()
+		go workerOnuInfo(&wg, db, host)
 	}
 
 	wg.Wait()
@@ -75,17 +103,23 @@ func CronOnuInfo(db models.ConnDb, caller string) error {
 	return nil
 }
 
-func workerZteOnuInfo(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo) {
+func workerOnuInfo(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo) {
 	defer wg.Done()
 
 	defer func() {
 		// recover from panic if one occured. Set err to nil otherwise.
 		if recover() != nil {
-			utils.Logline("error on this subprocess - workerOnusInfoZte", host.Ip.String())
+			utils.Logline("error on this subprocess - workerOnuInfo", host.Ip.String())
 			return
 		}
 	}()
 
+	driver, err := getDriver(host.Vendor)
+	if err != nil {
+		utils.Logline("error getting driver", host.Ip.String(), host.Name, err)
+		return
+	}
+
 	// Create a context with a timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 40*time.Second)
 	defer cancel()
@@ -94,25 +128,41 @@ func workerZteOnuInfo(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo
 	errChan := make(chan error, 1)
 
 	go func() {
-		var queryInternal, oid string
-		var cont, totalItems int
+		var queryInternal string
+		var cont int
 
-		//getItems onu from DB
-		items, err := getOnusInfoItems(db, host.Id)
+		//get every column needed on this run in one pass: onus-names and onus-status every time, onus-rx every 5min, onus-sn every 30min and the extra items of the vendor
+		minute := time.Now().Minute()
+		names := []string{"onu-name", "onu-status"}
+		if (minute-1)%5 == 0 {
+			names = append(names, "onu-rx")
+		}
+		if (minute-2)%30 == 0 {
+			names = append(names, "onu-sn")
+		}
+		for _, extra := range onuExtraItems {
+			if (minute-extra.offset)%extra.every == 0 && hasMetric(host, extra.metric) {
+				names = append(names, extra.metric)
+			}
+		}
+		table, err := driver.OnuTable(host, names)
 		if err != nil {
-			utils.Logline("error getting host - items from olts", host.Ip.String(), host.Name, err)
-			errChan <- err
-			return
+			if len(table) == 0 {
+				errChan <- err
+				return
+			}
+			//keep the rows already read, the rest of the onus are read on the next run
+			utils.Logline(fmt.Sprintf("partial snmp onu table (%d) rows", len(table)), host.Ip.String(), host.Name, err)
 		}
+		onuNames := table.column("onu-name")
 
-		//connect to snmp
-		connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.SnmpCommunity, 10, 10, true)
+		//getItems onu from DB
+		items, err := getOnusInfoItems(db, host.Id)
 		if err != nil {
-			utils.Logline("Couldnt establish connection", host.Name, err)
+			utils.Logline("error getting host - items from olts", host.Ip.String(), host.Name, err)
 			errChan <- err
 			return
 		}
-		defer connSnmp.Conn.Close()
 
 		//create context
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -128,25 +178,13 @@ func workerZteOnuInfo(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo
 		defer func() {
 			if err != nil {
 				tx1.Rollback(ctx) // Rollback if there's an error
-				utils.Logline("transaction rolled back due to error", host.Ip.String(), host.Name, "getZteOnuInfo", err)
+				utils.Logline("transaction rolled back due to error", host.Ip.String(), host.Name, "getOnuInfo", err)
 			}
 		}()
 
-		//get onus Names
-		oid = ".1.3.6.1.4.1.3902.1082.500.10.2.3.3.1.2"
-		resultSnmp, err := connSnmp.BulkWalkAll(oid)
-		if err != nil {
-			// connSnmp.Conn.Close() // close snmp connection if there's an error
-			utils.Logline("Error performing BulkWalk: ", host.Ip.String(), host.Name, oid, err)
-			errChan <- err
-			return
-		}
-		for _, value := range resultSnmp {
-			totalItems++
-			snmpOid := value.Name
-			snmpIndex := strings.ReplaceAll(snmpOid, ".1.3.6.1.4.1.3902.1082.500.10.2.3.3.1.2.", "")
-			snmpOnuName := fmt.Sprintf("%s", value.Value)
-			onuOldId, err := oldIdFromOnuName(snmpOnuName)
+		for _, onu := range onuNames {
+			snmpIndex := onu.snmpIndex
+			onuOldId, err := oldIdFromOnuName(onu.value)
 			if err != nil {
 				utils.Logline("error extracting oldId", host.Ip.String(), host.Name, err)
 				continue
@@ -188,77 +226,28 @@ func workerZteOnuInfo(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo
 				}
 			}
 
-			//check if itemEthlist exist
-			if onuItemDb := findOnuBy(items, "itemEthlist", snmpIndex); onuItemDb == nil && len(onuOldId) > 2 {
-				cont++
-				utils.Logline(host.Name, fmt.Sprintf(`INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, %s, 'onu-ethlist', %s, %s)`, host.Id, snmpIndex, onuOldId))
-
-				queryInternal = `INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, $1, 'onu-ethlist', $2, $3)`
-				if _, err := tx1.Exec(ctx, queryInternal, host.Id, snmpIndex, onuOldId); err != nil {
-					utils.Logline("error inserting network.host_item", host.Ip.String(), host.Name, err)
-					tx1.Rollback(ctx)
-					errChan <- err
-					return
-				}
-			}
-
-			//check if itemOnuName exist
-			if onuItemDb := findOnuBy(items, "itemOnuName", snmpIndex); onuItemDb == nil && len(onuOldId) > 2 {
-				cont++
-				utils.Logline(host.Name, fmt.Sprintf(`INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, %s, 'onu-name', %s, %s)`, host.Id, snmpIndex, onuOldId))
-
-				queryInternal = `INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, $1, 'onu-name', $2, $3)`
-				if _, err := tx1.Exec(ctx, queryInternal, host.Id, snmpIndex, onuOldId); err != nil {
-					utils.Logline("error inserting network.host_item", host.Ip.String(), host.Name, err)
-					tx1.Rollback(ctx)
-					errChan <- err
-					return
-				}
-			}
-
-			//check if item onuTx exist
-			if onuItemDb := findOnuBy(items, "itemOnuTx", snmpIndex); onuItemDb == nil && len(onuOldId) > 2 {
-				cont++
-				utils.Logline(host.Name, fmt.Sprintf(`INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, %s, 'onu-tx', %s, %s)`, host.Id, snmpIndex, onuOldId))
-
-				queryInternal = `INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, $1, 'onu-tx', $2, $3)`
-				if _, err := tx1.Exec(ctx, queryInternal, host.Id, snmpIndex, onuOldId); err != nil {
-					tx1.Rollback(ctx)
-					utils.Logline("error inserting network.host_item", host.Ip.String(), host.Name, err)
-					errChan <- err
-					return
-				}
-			}
-
-			//check if item onuRx exist
-			if onuItemDb := findOnuBy(items, "itemOnuRx", snmpIndex); onuItemDb == nil && len(onuOldId) > 2 {
-				cont++
-				utils.Logline(host.Name, fmt.Sprintf(`INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, %s, 'onu-rx', %s, %s)`, host.Id, snmpIndex, onuOldId))
-
-				queryInternal = `INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, $1, 'onu-rx', $2, $3)`
-				if _, err := tx1.Exec(ctx, queryInternal, host.Id, snmpIndex, onuOldId); err != nil {
-					tx1.Rollback(ctx)
-					utils.Logline("error inserting network.host_item", host.Ip.String(), host.Name, err)
-					errChan <- err
-					return
+			//check if the rest of items of the onu exist
+			itemsCreated := onuItemsCreated
+			for _, extra := range onuExtraItems {
+				if hasMetric(host, extra.metric) {
+					itemsCreated = append(itemsCreated, extra.item)
 				}
 			}
+			for _, itemName := range itemsCreated {
+				if onuItemDb := findOnuBy(items, itemName, snmpIndex); onuItemDb == nil && len(onuOldId) > 2 {
+					cont++
+					utils.Logline(host.Name, fmt.Sprintf(`INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, %s, '%s', %s, %s)`, host.Id, onuItemNames[itemName], snmpIndex, onuOldId))
 
-			//check if item onuStatus exist
-			if onuItemDb := findOnuBy(items, "itemOnuStatus", snmpIndex); onuItemDb == nil && len(onuOldId) > 2 {
-				cont++
-				utils.Logline(host.Name, fmt.Sprintf(`INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, %s, 'onu-status', %s, %s)`, host.Id, snmpIndex, onuOldId))
-
-				queryInternal = `INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, $1, 'onu-status', $2, $3)`
-				if _, err := tx1.Exec(ctx, queryInternal, host.Id, snmpIndex, onuOldId); err != nil {
-					tx1.Rollback(ctx)
-					utils.Logline("error inserting network.host_item", host.Ip.String(), host.Name, err)
-					errChan <- err
-					return
+					queryInternal = `INSERT INTO network.host_item (empresa_id, host_id, nombre, sn, oldid) VALUES (1, $1, $2, $3, $4)`
+					if _, err := tx1.Exec(ctx, queryInternal, host.Id, onuItemNames[itemName], snmpIndex, onuOldId); err != nil {
+						utils.Logline("error inserting network.host_item", host.Ip.String(), host.Name, err)
+						tx1.Rollback(ctx)
+						errChan <- err
+						return
+					}
 				}
 			}
 		}
-		// connSnmp.Conn.Close()
 
 		//commit transaction
 		err = tx1.Commit(ctx)
@@ -280,75 +269,33 @@ func workerZteOnuInfo(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo
 		}
 
 		// insert into db, onus-names every 30min
-		if time.Now().Minute()%30 == 0 {
-			var cont int
-
-			//open a transaction
-			tx2, err := db.Conn.Begin(ctx)
-			if err != nil {
-				utils.Logline("error starting transaction", host.Ip.String(), host.Name, err)
-				errChan <- err
-				return
-			}
-			defer func() {
-				if err != nil {
-					tx2.Rollback(ctx) // Rollback if there's an error
-					utils.Logline("transaction rolled back due to error", host.Ip.String(), host.Name, "InsertZteOnuName", err)
-				}
-			}()
-
-			for _, value := range resultSnmp {
-				snmpIndex := strings.ReplaceAll(value.Name, ".1.3.6.1.4.1.3902.1082.500.10.2.3.3.1.2.", "")
-				snmpValue := fmt.Sprintf("%s", value.Value)
+		if minute%30 == 0 {
+			insertOnuValues(db, host, items, onuNames, "itemOnuName", "detalle_text", "onuNames")
+		}
 
-				//get host_item.id and create sql for transaction
-				if onuItemDb := findOnuBy(items, "itemOnuName", snmpIndex); onuItemDb != nil {
-					cont++
-					queryInternal = `INSERT INTO estadistica.detalle_text (item_id, value) VALUES ($1, $2)`
-					if _, err := tx2.Exec(ctx, queryInternal, onuItemDb.itemId, snmpValue); err != nil {
-						utils.Logline("error inserting estadistica.detalle_text", host.Ip.String(), host.Name, err)
-						tx2.Rollback(ctx)
-						errChan <- err
-						return
-					}
-				}
+		// insert into db, onus-status every time this cron runs, onus-rx every 5min and onus-sn every 30min
+		insertOnuValues(db, host, items, table.column("onu-status"), "itemOnuStatus", "detalle_int", "onuStatus")
+		if (minute-1)%5 == 0 {
+			insertOnuValues(db, host, items, table.column("onu-rx"), "itemOnuRx", "detalle_int", "onuOptics")
+		}
+		if (minute-2)%30 == 0 {
+			insertOnuValues(db, host, items, table.column("onu-sn"), "itemSn", "detalle_text", "onuSerials")
+		}
+		for _, extra := range onuExtraItems {
+			if values := table.column(extra.metric); len(values) > 0 {
+				insertOnuValues(db, host, items, values, extra.item, extra.table, "onuExtra/"+extra.metric)
 			}
+		}
 
-			//commit transaction
-			err = tx2.Commit(ctx)
+		// insert into db, onus-ethlist every 30min
+		if (minute-5)%30 == 0 && hasMetric(host, "onu-eth-link") {
+			ethTable, err := driver.OnuTable(host, onuEthMetrics)
 			if err != nil {
-				errChan <- err
-				return
+				utils.Logline("error getting ethernet ports of onus", host.Ip.String(), host.Name, err)
 			}
-
-			utils.Logline(fmt.Sprintf("(%d) records inserted of onu-status and onu-name", cont), host.Ip.String(), host.Name, "get_onu_info", "zteOnusName")
-		}
-
-		var wgInternal sync.WaitGroup
-
-		// insert into db, onus-status every time this cron runs
-		wgInternal.Add(1)
-		go func() {
-			zteOnusStatus(&wgInternal, db, host, items, totalItems)
-		}()
-
-		// insert into db, onus-rx every 5min
-		if (time.Now().Minute()-1)%5 == 0 {
-			wgInternal.Add(1)
-			go func() {
-				zteOnusRx(&wgInternal, db, host, items, totalItems)
-			}()
-		}
-
-		// insert into db, onus-sn every 30min
-		if (time.Now().Minute()-2)%30 == 0 {
-			wgInternal.Add(1)
-			go func() {
-				zteOnusSn(&wgInternal, db, host, items, totalItems)
-			}()
+			insertOnuValues(db, host, items, onuEthList(ethTable), "itemEthlist", "detalle_text", "onuEthlist")
 		}
 
-		wgInternal.Wait()
 		errChan <- nil
 	}()
 
@@ -359,285 +306,61 @@ func workerZteOnuInfo(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo
 		utils.Logline("timeout occurred while processing snmp on", host.Ip.String(), host.Name, ctx.Err())
 		return
 	case err := <-errChan:
-		if err != nil {
-			utils.Logline("error processing snmp on", host.Ip.String(), host.Name, err)
+		if errors.Is(err, errDriverUnsupported) {
+			utils.Logline(host.Vendor+" worker on construction", host.Ip.String(), host.Name)
 			return
 		}
-	}
-}
-
-func zteOnusStatus(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo, items []itemsCronOnu, totalItems int) {
-	defer wg.Done()
-
-	//create context
-	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
-	defer cancel()
-
-	//connect to snmp
-	connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.SnmpCommunity, 20, 20, false)
-	if err != nil {
-		utils.Logline("Couldnt establish connection", host.Ip.String(), host.Name, "zteOnusStatus", err)
-		return
-	}
-	defer connSnmp.Conn.Close()
-
-	//open a transaction
-	tx, err := db.Conn.Begin(ctx)
-	if err != nil {
-		utils.Logline("error starting transaction", host.Ip.String(), host.Name, "zteOnusStatus", err)
-		return
-	}
-	defer func() {
 		if err != nil {
-			tx.Rollback(ctx) // Rollback if there's an error
-			utils.Logline("transaction rolled back due to error", host.Name, "getZteOnuStatus", err)
-		}
-	}()
-
-	//save onu-status every time this cron runs
-	var cont int
-	oid := ".1.3.6.1.4.1.3902.1082.500.10.2.3.8.1.4" // get onus status
-	itemsRetrieved := 0
-	for itemsRetrieved < totalItems {
-		select {
-		case <-ctx.Done():
-			utils.Logline(fmt.Sprintf("timeout occurred while processing snmp zteOnusStatus itemsTotal (%d)", itemsRetrieved), host.Ip.String(), host.Name, ctx.Err())
-			tx.Rollback(ctx)
-			return
-		default:
-			// Continue with the operation
-		}
-
-		result, err := connSnmp.GetBulk([]string{oid}, 0, connSnmp.MaxRepetitions)
-		if err != nil {
-			// connSnmp.Conn.Close() // close snmp connection if there's an error
-			utils.Logline("Error performing BulkWalk: ", host.Ip.String(), host.Name, "zteOnusStatus", oid, err)
-
-			tx.Rollback(ctx) // Rollback if there's an error
-			utils.Logline("transaction rolled back due to error", host.Name, "getZteOnuStatus", err)
+			utils.Logline("error processing snmp on", host.Ip.String(), host.Name, err)
 			return
 		}
-
-		for _, value := range result.Variables {
-			// fmt.Printf("OID: %s, Type: %s, Value: %v\n", value.Name, value.Type, value.Value)
-			if strings.Contains(value.Name, ".1.3.6.1.4.1.3902.1082.500.10.2.3.8.1.4.") {
-				snmpIndex := strings.ReplaceAll(value.Name, ".1.3.6.1.4.1.3902.1082.500.10.2.3.8.1.4.", "")
-				snmpValue := fmt.Sprintf("%d", value.Value)
-
-				//get host_item.id and create sql for transaction
-				if onuItemDb := findOnuBy(items, "itemOnuStatus", snmpIndex); onuItemDb != nil {
-					cont++
-					queryInternal := `INSERT INTO estadistica.detalle_int (item_id, value) VALUES ($1, $2)`
-					if _, err := tx.Exec(ctx, queryInternal, onuItemDb.itemId, snmpValue); err != nil {
-						utils.Logline("error inserting estadistica.detalle_int", host.Ip.String(), host.Name, "zteOnusStatus", err)
-						tx.Rollback(ctx)
-						return
-					}
-				}
-			}
-			itemsRetrieved++
-			oid = value.Name // Update OID for the next GetBulk request
-		}
-
-		if uint32(len(result.Variables)) < connSnmp.MaxRepetitions || !strings.Contains(oid, ".1.3.6.1.4.1.3902.1082.500.10.2.3.8.1.4.") {
-			break // No more items to retrieve
-		}
-	}
-
-	// commit transaction
-	err = tx.Commit(ctx)
-	if err != nil {
-		utils.Logline("error executing the transaction", host.Ip.String(), host.Name, "zteOnusStatus", err)
-		return
 	}
-
-	utils.Logline(fmt.Sprintf("(%d) records inserted of onu-status", cont), host.Ip.String(), host.Name, "get_onu_info", "zteOnusStatus")
 }
 
-func zteOnusRx(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo, items []itemsCronOnu, totalItems int) {
-	defer wg.Done()
-
+// funcion para insertar en estadistica.detalle_int o detalle_text los valores de las onus
+func insertOnuValues(db models.ConnDb, host models.HostInfo, items []itemsCronOnu, values []onuValue, itemName string, table string, taskName string) {
 	//create context
-	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	//connect to snmp
-	connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.SnmpCommunity, 20, 20, false)
-	if err != nil {
-		utils.Logline("Couldnt establish connection", host.Ip.String(), host.Name, "zteOnusRx", err)
-		return
-	}
-	defer connSnmp.Conn.Close()
-
 	//open a transaction
 	tx, err := db.Conn.Begin(ctx)
 	if err != nil {
-		utils.Logline("error starting transaction", host.Ip.String(), host.Name, "zteOnusRx", err)
+		utils.Logline("error starting transaction", host.Ip.String(), host.Name, taskName, err)
 		return
 	}
 	defer func() {
 		if err != nil {
 			tx.Rollback(ctx) // Rollback if there's an error
-			utils.Logline("transaction rolled back due to error", host.Ip.String(), host.Name, "getZteOnuRx", err)
+			utils.Logline("transaction rolled back due to error", host.Ip.String(), host.Name, taskName, err)
 		}
 	}()
 
-	var cont int
-	oid := ".1.3.6.1.4.1.3902.1082.500.1.2.4.2.1.2" // get onus rxs
-	itemsRetrieved := 0
-	for itemsRetrieved < totalItems {
-		select {
-		case <-ctx.Done():
-			utils.Logline(fmt.Sprintf("timeout occurred while processing snmp getZteOnuRx itemsTotal (%d)", itemsRetrieved), host.Ip.String(), host.Name, ctx.Err())
-			tx.Rollback(ctx)
-			return
-		default:
-			// Continue with the operation
-		}
-
-		result, err := connSnmp.GetBulk([]string{oid}, 0, connSnmp.MaxRepetitions)
-		if err != nil {
-			// connSnmp.Conn.Close() // close snmp connection if there's an error
-			utils.Logline("Error performing BulkWalk: ", host.Ip.String(), host.Name, "getZteOnuRx", oid, err)
-
-			tx.Rollback(ctx) // Rollback if there's an error
-			utils.Logline("transaction rolled back due to error", "getZteOnuRx", host.Name, err)
-			return
-		}
-
-		for _, value := range result.Variables {
-			// fmt.Printf("OID: %s, Type: %s, Value: %v\n", value.Name, value.Type, value.Value)
-			if strings.Contains(value.Name, ".1.3.6.1.4.1.3902.1082.500.1.2.4.2.1.2.") {
-				snmpIndex := strings.ReplaceAll(value.Name, ".1.3.6.1.4.1.3902.1082.500.1.2.4.2.1.2.", "")
-				valueInt, err := strconv.ParseFloat(fmt.Sprintf("%d", value.Value), 32)
-				if err != nil {
-					utils.Logline("error converting rxValue to int", host.Ip.String(), host.Name, "zteOnusRx", value.Name, fmt.Sprintf("%d", value.Value), err)
-					continue
-				}
-
-				//divir el numero entre 1000 ya que se recibe de snmp el valor sin separador de decimales
-				snmpValue := "0"
-				if valueInt != 0 {
-					snmpValue = fmt.Sprintf("%.2f", valueInt/1000)
-				}
-
-				//get host_item.id and create sql for transaction
-				if onuItemDb := findOnuBy(items, "itemOnuRx", snmpIndex); onuItemDb != nil {
-					cont++
-					queryInternal := `INSERT INTO estadistica.detalle_int (item_id, value) VALUES ($1, $2)`
-					if _, err := tx.Exec(ctx, queryInternal, onuItemDb.itemId, snmpValue); err != nil {
-						utils.Logline(host.Name, fmt.Sprintf(`INSERT INTO estadistica.detalle_int (item_id, value) VALUES (%s, %s)`, onuItemDb.itemId, snmpValue))
-						utils.Logline("error inserting estadistica.detalle_int", host.Ip.String(), host.Name, "zteOnusRx", err)
-						tx.Rollback(ctx)
-						return
-					}
-				}
-			}
-			itemsRetrieved++
-			oid = value.Name // Update OID for the next GetBulk request
-		}
-
-		if uint32(len(result.Variables)) < connSnmp.MaxRepetitions || !strings.Contains(oid, ".1.3.6.1.4.1.3902.1082.500.1.2.4.2.1.2.") {
-			break // No more items to retrieve
-		}
+	queryInternal := `INSERT INTO estadistica.detalle_int (item_id, value) VALUES ($1, $2)`
+	if table == "detalle_text" {
+		queryInternal = `INSERT INTO estadistica.detalle_text (item_id, value) VALUES ($1, $2)`
 	}
 
-	//commit transaction
-	err = tx.Commit(ctx)
-	if err != nil {
-		utils.Logline("error executing the transaction", host.Ip.String(), host.Name, "zteOnusRx", err)
-		return
-	}
-
-	utils.Logline(fmt.Sprintf("(%d) records inserted of onu-rx", cont), host.Ip.String(), host.Name, "get_onu_info", "zteOnusRx")
-
-}
-
-func zteOnusSn(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo, items []itemsCronOnu, totalItems int) {
-	defer wg.Done()
-
-	//create context
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	//connect to snmp
-	connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.SnmpCommunity, 20, 20, false)
-	if err != nil {
-		utils.Logline("Couldnt establish connection", host.Ip.String(), host.Name, "zteOnusSn", err)
-		return
-	}
-	defer connSnmp.Conn.Close()
-
-	//open a transaction
-	tx, err := db.Conn.Begin(ctx)
-	if err != nil {
-		utils.Logline("error starting transaction", host.Ip.String(), host.Name, "zteOnusSn", err)
-		return
-	}
-	defer func() {
-		if err != nil {
-			tx.Rollback(ctx) // Rollback if there's an error
-			utils.Logline("transaction rolled back due to error", host.Ip.String(), host.Name, "getZteOnuSn", err)
-		}
-	}()
-
 	var cont int
-	oid := ".1.3.6.1.4.1.3902.1082.500.10.2.3.3.1.18" // get onus sns
-	itemsRetrieved := 0
-	for itemsRetrieved < totalItems {
-		select {
-		case <-ctx.Done():
-			utils.Logline(fmt.Sprintf("timeout occurred while processing snmp zteOnusSn itemsTotal (%d)", itemsRetrieved), host.Ip.String(), host.Name, ctx.Err())
-			tx.Rollback(ctx)
-			return
-		default:
-			// Continue with the operation
-		}
-
-		result, err := connSnmp.GetBulk([]string{oid}, 0, connSnmp.MaxRepetitions)
-		if err != nil {
-			// connSnmp.Conn.Close() // close snmp connection if there's an error
-			utils.Logline("Error performing BulkWalk: ", host.Ip.String(), "zteOnusSn", host.Name, oid, err)
-
-			tx.Rollback(ctx) // Rollback if there's an error
-			utils.Logline("transaction rolled back due to error", "zteOnusSn", host.Name, err)
-			return
-		}
-
-		for _, value := range result.Variables {
-			// fmt.Printf("OID: %s, Type: %s, Value: %v\n", value.Name, value.Type, value.Value)
-			if strings.Contains(value.Name, ".1.3.6.1.4.1.3902.1082.500.10.2.3.3.1.18.") {
-				snmpIndex := strings.ReplaceAll(value.Name, ".1.3.6.1.4.1.3902.1082.500.10.2.3.3.1.18.", "")
-				snmpValue := fmt.Sprintf("%s", value.Value)
-
-				//get host_item.id and create sql for transaction
-				if onuItemDb := findOnuBy(items, "itemSn", snmpIndex); onuItemDb != nil {
-					cont++
-					queryInternal := `INSERT INTO estadistica.detalle_text (item_id, value) VALUES ($1, $2)`
-					if _, err := tx.Exec(ctx, queryInternal, onuItemDb.itemId, snmpValue); err != nil {
-						utils.Logline("error inserting estadistica.detalle_text", host.Ip.String(), host.Name, "zteOnusSn", err)
-						tx.Rollback(ctx)
-						return
-					}
-				}
+	for _, value := range values {
+		//get host_item.id and create sql for transaction
+		if onuItemDb := findOnuBy(items, itemName, value.snmpIndex); onuItemDb != nil {
+			cont++
+			if _, err = tx.Exec(ctx, queryInternal, onuItemDb.itemId, value.value); err != nil {
+				utils.Logline("error inserting estadistica."+table, host.Ip.String(), host.Name, taskName, err)
+				return
 			}
-			itemsRetrieved++
-			oid = value.Name // Update OID for the next GetBulk request
-		}
-
-		if uint32(len(result.Variables)) < connSnmp.MaxRepetitions || !strings.Contains(oid, ".1.3.6.1.4.1.3902.1082.500.10.2.3.3.1.18.") {
-			break // No more items to retrieve
 		}
 	}
 
 	//commit transaction
 	err = tx.Commit(ctx)
 	if err != nil {
+		utils.Logline("error executing the transaction", host.Ip.String(), host.Name, taskName, err)
 		return
 	}
 
-	utils.Logline(fmt.Sprintf("(%d) records inserted of onu-sn", cont), host.Ip.String(), host.Name, "getOnuInfo", "zteOnusSn")
-
+	utils.Logline(fmt.Sprintf("(%d) records inserted on estadistica.%s", cont, table), host.Ip.String(), host.Name, "get_onu_info", taskName)
 }
 
 func getOnusInfoItems(db models.ConnDb, hostId string) ([]itemsCronOnu, error) {
@@ -671,6 +394,35 @@ func getOnusInfoItems(db models.ConnDb, hostId string) ([]itemsCronOnu, error) {
 	return items, nil
 }
 
+// onuEthList groups the ethernet ports by onu and returns the json of the ports of every onu
+func onuEthList(table metricTable) []onuValue {
+	ports := map[string][]onuEthPort{}
+	for index, row := range table {
+		//the last part of the index is the number of port
+		pos := strings.LastIndex(index, ".")
+		if pos <= 0 {
+			continue
+		}
+		port, err := strconv.Atoi(index[pos+1:])
+		if err != nil {
+			continue
+		}
+		snmpIndex := index[:pos]
+		ports[snmpIndex] = append(ports[snmpIndex], onuEthPort{Port: port, Admin: row["onu-eth-admin"], Link: row["onu-eth-link"], Speed: row["onu-eth-speed"], Duplex: row["onu-eth-duplex"]})
+	}
+
+	var values []onuValue
+	for snmpIndex, list := range ports {
+		sort.Slice(list, func(i, j int) bool { return list[i].Port < list[j].Port })
+		data, err := json.Marshal(list)
+		if err != nil {
+			continue
+		}
+		values = append(values, onuValue{snmpIndex: snmpIndex, value: string(data)})
+	}
+	return values
+}
+
 func oldIdFromOnuName(onuName string) (string, error) {
 	parts := strings.Split(onuName, "_-_")
 	if len(parts) <= 1 {
@@ -686,42 +438,13 @@ func oldIdFromOnuName(onuName string) (string, error) {
 }
 
 func findOnuBy(items []itemsCronOnu, targetItemName string, targetSnmpIndex string) *itemsCronOnu {
-	switch targetItemName {
-	case "itemSn":
-		for _, item := range items {
-			if item.itemSn.String == targetSnmpIndex && item.itemNombre.String == "onu-sn" {
-				return &item
-			}
-		}
-	case "itemEthlist":
-		for _, item := range items {
-			if item.itemSn.String == targetSnmpIndex && item.itemNombre.String == "onu-ethlist" {
-				return &item
-			}
-		}
-	case "itemOnuName":
-		for _, item := range items {
-			if item.itemSn.String == targetSnmpIndex && item.itemNombre.String == "onu-name" {
-				return &item
-			}
-		}
-	case "itemOnuTx":
-		for _, item := range items {
-			if item.itemSn.String == targetSnmpIndex && item.itemNombre.String == "onu-tx" {
-				return &item
-			}
-		}
-	case "itemOnuRx":
-		for _, item := range items {
-			if item.itemSn.String == targetSnmpIndex && item.itemNombre.String == "onu-rx" {
-				return &item
-			}
-		}
-	case "itemOnuStatus":
-		for _, item := range items {
-			if item.itemSn.String == targetSnmpIndex && item.itemNombre.String == "onu-status" {
-				return &item
-			}
+	nombre, ok := onuItemNames[targetItemName]
+	if !ok {
+		return nil
+	}
+	for _, item := range items {
+		if item.itemSn.String == targetSnmpIndex && item.itemNombre.String == nombre {
+			return &item
 		}
 	}
 
@@ -730,10 +453,12 @@ func findOnuBy(items []itemsCronOnu, targetItemName string, targetSnmpIndex stri
 
 // onu-status		every 1min
 // onu-rx				every 5min
+// onu-tx, onu-rxonu, onu-temperature, onu-voltage, onu-bias	every 15min
+// onu-lastonline, onu-lastoffline, onu-downcause	every 5min
+// onu-distance	every 30min
 // onu-name			every 30min
 // onu-sn				every 30min
-// onu-ethlist	sinc-manual
-// onu-tx				sinc-manual
+// onu-ethlist	every 30min, json with the ethernet ports
 
 // ".1.3.6.1.4.1.3902.1082.500.10.2.3.8.1.4", //onusStatus
 // 	// logging (1)
@@ -2,11 +2,13 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"strconv"
 	"sync"
 	"time"
 
+	g "github.com/gosnmp/gosnmp"
 	"ired.com/olt/models"
 	"ired.com/olt/utils"
 )
@@ -20,56 +22,49 @@ type itemsTrafficOnu struct {
 	TxPktRate   int64
 }
 
-type itemsTrafficResult struct {
-	snmpIndex string
-	valueData string
-	valueType string
+// octet and packet counters of one onu, used by the vendors that only expose counters instead of rates
+// and by zte with onu_traffic_mode counters
+type onuCounters struct {
+	sampledAt time.Time
+	oltUptime uint64 // sysUpTime of the olt in timeticks when the counters were read, used to detect reboots
+	rxOctets  uint64
+	txOctets  uint64
+	rxPkts    uint64
+	txPkts    uint64
 }
 
+// last sample of counters of every onu keyed by hostId and snmpIndex, needed to calculate the rates on the next run.
+// it is persisted on estadistica.traffic_onu_counter, loaded keeps the hosts already read from there
+var onuCountersPrev = struct {
+	sync.Mutex
+	samples map[string]map[string]onuCounters
+	loaded  map[string]bool
+}{samples: map[string]map[string]onuCounters{}, loaded: map[string]bool{}}
+
+// usesOnuCounters returns if the traffic of the onus of the olt is calculated from counters, only zte can use the rates of the olt
+func usesOnuCounters(host models.HostInfo) bool {
+	return host.Vendor != "zte" || host.TrafficMode == "counters"
+}
+
+// rates above this are discarded, they come from counters cleared by hand on the olt
+const onuTrafficMaxKbps = 10000000
+
 func CronOnuTraffic(db models.ConnDb, caller string) error {
 	//show status of worker
 	utils.Logline(utils.ShowStatusWorker(db, "onuTraffic", caller+"/begin"))
 
 	//get olts to work on
-	query := `SELECT h.id, h.ip, h.nombre, h.info->>'telnet_username' as username, h.info->>'telnet_password' as password, h.info->>'snmp_read_community' as community
-		FROM network.host as h
-		WHERE h.info->>'telnet_username' IS NOT NULL AND h.info->>'snmp_read_community' IS NOT NULL AND h.activo=true
-		ORDER BY RANDOM()`
-	rows, err := db.Conn.Query(db.Ctx, query)
+	hostsInfo, err := getOltHosts(db.Ctx, db.Conn)
 	if err != nil {
 		utils.Logline("error getting host to run cron", err)
 		return err
 	}
-	defer rows.Close()
-
-	//create slice of hosts
-	var hostsInfo []models.HostInfo
-	for rows.Next() {
-		var host models.HostInfo
-		err = rows.Scan(&host.Id, &host.Ip, &host.Name, &host.TelnetUsername, &host.TelnetPasswd, &host.SnmpCommunity)
-		if err != nil {
-			utils.Logline("error scanning rows of host olts", err)
-			return err
-		}
-		hostsInfo = append(hostsInfo, host)
-	}
-	rows.Close()
 
 	//iterate over hosts and create one goroutine for every olt
 	var wg sync.WaitGroup
 	for _, host := range hostsInfo {
 		wg.Add(1)
-		go func() {
-			if host.TelnetUsername == "vsol" {
-				utils.Logline("vsol worker on construction", host.Ip.String(), host.Name)
-				wg.Done()
-			} else if host.TelnetUsername == "cdata" {
-				utils.Logline("cdata worker on construction", host.Ip.String(), host.Name)
-				wg.Done()
-			} else {
-				workerZteOnuTraffic(&wg, db, host)
-			}
-		}()
+		go workerOnuTraffic(&wg, db, host)
 	}
 
 	wg.Wait()
@@ -80,17 +75,23 @@ func CronOnuTraffic(db models.ConnDb, caller string) error {
 	return nil
 }
 
-func workerZteOnuTraffic(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo) {
+func workerOnuTraffic(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo) {
 	defer wg.Done()
 
 	defer func() {
 		// recover from panic if one occured. Set err to nil otherwise.
 		if recover() != nil {
-			utils.Logline("error on this subprocess - workerOnusTrafficZte", host.Ip.String(), host.Name)
+			utils.Logline("error on this subprocess - workerOnuTraffic", host.Ip.String(), host.Name)
 			return
 		}
 	}()
 
+	driver, err := getDriver(host.Vendor)
+	if err != nil {
+		utils.Logline("error getting driver", host.Ip.String(), host.Name, err)
+		return
+	}
+
 	// Create a context with a timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 57*time.Second)
 	defer cancel()
@@ -99,64 +100,24 @@ func workerZteOnuTraffic(wg *sync.WaitGroup, db models.ConnDb, host models.HostI
 	errChan := make(chan error, 1)
 
 	go func() {
-		var items []itemsTrafficOnu
-		var totalItems int
-
-		//connect to snmp
-		connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.SnmpCommunity, 20, 20, true)
-		if err != nil {
-			utils.Logline("Couldnt establish connection", "OnuTraffic", host.Name, err)
-			errChan <- err
-			return
+		//previous samples of counters after a restart of the service
+		if usesOnuCounters(host) {
+			if err := loadOnuCounters(db, host); err != nil {
+				utils.Logline("error loading previous counters of onus", host.Ip.String(), host.Name, "OnuTraffic", err)
+			}
 		}
-		defer connSnmp.Conn.Close()
 
-		//get onus SN
-		oid := ".1.3.6.1.4.1.3902.1082.500.10.2.3.3.1.18"
-		resultSnmp, err := connSnmp.BulkWalkAll(oid)
+		//get traffic of every onu from the olt
+		items, err := driver.OnuTraffic(host)
 		if err != nil {
-			utils.Logline("Error performing BulkWalk: ", host.Ip.String(), host.Name, "OnuTraffic", oid, err)
 			errChan <- err
 			return
 		}
-		for _, value := range resultSnmp {
-			snmpOid := value.Name
-			snmpIndex := strings.ReplaceAll(snmpOid, ".1.3.6.1.4.1.3902.1082.500.10.2.3.3.1.18.", "")
-			snmpOnuSnPart := strings.Split(fmt.Sprintf("%s", value.Value), ",")
-			snmpOnuSn := snmpOnuSnPart[len(snmpOnuSnPart)-1]
-
-			if onu := findItemBySn(items, snmpIndex); onu == nil {
-				items = append(items, itemsTrafficOnu{snmpIndex: snmpIndex, onuSn: snmpOnuSn, RxOctetRate: 0, TxOctetRate: 0, RxPktRate: 0, TxPktRate: 0})
-				totalItems++
-			}
-		}
-		connSnmp.Conn.Close()
-
-		//crear canal para recibir la respuesta de las operaciones en snmp
-		var wgInternal sync.WaitGroup
-		results := make(chan itemsTrafficResult, 300)
-
-		wgInternal.Add(1)
-		go zteOnusBytes(&wgInternal, host, totalItems, results)
-		wgInternal.Add(1)
-		go zteOnusPkts(&wgInternal, host, totalItems, results)
 
-		go func() {
-			wgInternal.Wait()
-			close(results)
-		}()
-
-		for result := range results {
-			index := findItemBySn(items, result.snmpIndex)
-			switch result.valueType {
-			case "RxOctetRate":
-				items[*index].RxOctetRate = utils.BytesToKb(result.valueData)
-			case "TxOctetRate":
-				items[*index].TxOctetRate = utils.BytesToKb(result.valueData)
-			case "RxPktRate":
-				items[*index].RxPktRate = utils.StringToInt64(result.valueData)
-			case "TxPktRate":
-				items[*index].TxPktRate = utils.StringToInt64(result.valueData)
+		//keep the samples of counters for the next run
+		if usesOnuCounters(host) {
+			if err := saveOnuCounters(db, host); err != nil {
+				utils.Logline("error saving counters of onus", host.Ip.String(), host.Name, "OnuTraffic", err)
 			}
 		}
 
@@ -207,6 +168,10 @@ func workerZteOnuTraffic(wg *sync.WaitGroup, db models.ConnDb, host models.HostI
 		utils.Logline("timeout occurred while processing snmp on", host.Ip.String(), host.Name, ctx.Err())
 		return
 	case err := <-errChan:
+		if errors.Is(err, errDriverUnsupported) {
+			utils.Logline(host.Vendor+" worker on construction", host.Ip.String(), host.Name)
+			return
+		}
 		if err != nil {
 			utils.Logline("error processing snmp on", host.Ip.String(), host.Name, err)
 			return
@@ -214,140 +179,192 @@ func workerZteOnuTraffic(wg *sync.WaitGroup, db models.ConnDb, host models.HostI
 	}
 }
 
-func zteOnusBytes(wg *sync.WaitGroup, host models.HostInfo, totalItems int, results chan<- itemsTrafficResult) {
-	defer wg.Done()
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 51*time.Second)
-	defer cancel()
-
-	//connect to snmp
-	connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.SnmpCommunity, totalItems, 20, false)
+// onuTrafficFromCounters walks the counters of the onus and calculates the rates against the previous sample over the real interval,
+// the onus without previous sample or read before a reboot of the olt are left out until the next run
+func onuTrafficFromCounters(host models.HostInfo, vendor string) ([]itemsTrafficOnu, error) {
+	//uptime of the olt, if it is lower than the one of the previous sample the olt was rebooted and the counters started again
+	uptime, err := oltUptime(host)
 	if err != nil {
-		utils.Logline("Couldnt establish connection", host.Ip.String(), host.Name, "zteOnusBytes", err)
-		return
+		return nil, err
 	}
-	defer connSnmp.Conn.Close()
 
-	oids := []string{
-		".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.3",  // zxAnPonOnuIfRxOctetRate
-		".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.46", // zxAnPonOnuIfTxOctetRate
+	//sn and counters rx and tx as seen from the olt on the same pass
+	names := []string{"onu-traffic-sn", "onu-rx-octets", "onu-tx-octets", "onu-rx-pkts", "onu-tx-pkts"}
+	table, err := fetchMetricTable(host, vendor, names)
+	if err != nil {
+		return nil, err
 	}
-	for _, oid := range oids {
-		itemsRetrieved := 0
-		for itemsRetrieved < totalItems {
-			select {
-			case <-ctx.Done():
-				utils.Logline(fmt.Sprintf("timeout occurred while processing snmp zteOnusBytes itemsTotal (%d)", itemsRetrieved), host.Ip.String(), host.Name, ctx.Err())
-				return
-			default:
-				// Continue with the operation
-			}
-			result, err := connSnmp.GetBulk([]string{oid}, 0, connSnmp.MaxRepetitions)
-			if err != nil {
-				// connSnmp.Conn.Close() // close snmp connection if there's an error
-				utils.Logline("Error performing BulkWalk: ", host.Ip.String(), host.Name, "zteOnusBytes", oid, err)
-				return
-			}
+	sampledAt := time.Now()
 
-			for _, value := range result.Variables {
-				// fmt.Printf("OID: %s, Type: %s, Value: %v\n", value.Name, value.Type, value.Value)
-				switch {
-				case strings.Contains(value.Name, ".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.3."):
-					results <- itemsTrafficResult{
-						snmpIndex: strings.ReplaceAll(value.Name, ".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.3.", ""),
-						valueData: fmt.Sprintf("%d", value.Value),
-						valueType: "RxOctetRate",
-					}
-				case strings.Contains(value.Name, ".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.46."):
-					results <- itemsTrafficResult{
-						snmpIndex: strings.ReplaceAll(value.Name, ".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.46.", ""),
-						valueData: fmt.Sprintf("%d", value.Value),
-						valueType: "TxOctetRate",
-					}
-				}
-				itemsRetrieved++
-				oid = value.Name // Update OID for the next GetBulk request
-			}
+	onuCountersPrev.Lock()
+	defer onuCountersPrev.Unlock()
 
-			if uint32(len(result.Variables)) < connSnmp.MaxRepetitions || (!strings.Contains(oid, "3902.1082.500.4.2.2.2.1.3.") && !strings.Contains(oid, "3902.1082.500.4.2.2.2.1.46.")) {
-				break // No more items to retrieve
-			}
+	//the samples of the onus no longer on the olt are dropped
+	samples := onuCountersPrev.samples[host.Id]
+	current := make(map[string]onuCounters, len(table))
+	onuCountersPrev.samples[host.Id] = current
+
+	var items []itemsTrafficOnu
+	for snmpIndex, row := range table {
+		onuSn, ok := row["onu-traffic-sn"]
+		if !ok {
+			continue
+		}
+		counters := onuCounters{
+			sampledAt: sampledAt,
+			oltUptime: uptime,
+			rxOctets:  utils.StringToUint64(row["onu-rx-octets"]),
+			txOctets:  utils.StringToUint64(row["onu-tx-octets"]),
+			rxPkts:    utils.StringToUint64(row["onu-rx-pkts"]),
+			txPkts:    utils.StringToUint64(row["onu-tx-pkts"]),
+		}
+
+		prev, ok := samples[snmpIndex]
+		current[snmpIndex] = counters
+		if !ok || counters.oltUptime < prev.oltUptime {
+			continue
+		}
+
+		seconds := counters.sampledAt.Sub(prev.sampledAt).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+
+		item := itemsTrafficOnu{
+			snmpIndex:   snmpIndex,
+			onuSn:       onuSn,
+			RxOctetRate: utils.BytesToKb(fmt.Sprintf("%f", float64(counterDelta(counters.rxOctets, prev.rxOctets, table.counterMax(snmpIndex, "onu-rx-octets")))/seconds)),
+			TxOctetRate: utils.BytesToKb(fmt.Sprintf("%f", float64(counterDelta(counters.txOctets, prev.txOctets, table.counterMax(snmpIndex, "onu-tx-octets")))/seconds)),
+			RxPktRate:   utils.FloatToInt64(float64(counterDelta(counters.rxPkts, prev.rxPkts, table.counterMax(snmpIndex, "onu-rx-pkts"))) / seconds),
+			TxPktRate:   utils.FloatToInt64(float64(counterDelta(counters.txPkts, prev.txPkts, table.counterMax(snmpIndex, "onu-tx-pkts"))) / seconds),
+		}
+		if item.RxOctetRate > onuTrafficMaxKbps || item.TxOctetRate > onuTrafficMaxKbps {
+			utils.Logline(fmt.Sprintf("discarding traffic of onu %s, counters cleared (%d/%d kbps)", snmpIndex, item.RxOctetRate, item.TxOctetRate), host.Ip.String(), host.Name)
+			continue
 		}
+		items = append(items, item)
 	}
-}
 
-func zteOnusPkts(wg *sync.WaitGroup, host models.HostInfo, totalItems int, results chan<- itemsTrafficResult) {
-	defer wg.Done()
+	return items, nil
+}
 
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 51*time.Second)
-	defer cancel()
+// counterDelta returns the increment of the counter since prev, if it is lower the counter wrapped after counterMax
+func counterDelta(current uint64, prev uint64, counterMax uint64) uint64 {
+	if current >= prev {
+		return current - prev
+	}
+	return counterMax - prev + current + 1
+}
 
-	//connect to snmp
-	connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.SnmpCommunity, totalItems, 20, false)
+// oltUptime returns sysUpTime.0 of the olt in timeticks
+func oltUptime(host models.HostInfo) (uint64, error) {
+	connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.Snmp, 1, 1, false)
 	if err != nil {
-		utils.Logline("Couldnt establish connection", host.Ip.String(), host.Name, "zteOnusPkts", err)
-		return
+		return 0, fmt.Errorf("couldnt establish connection: %w", err)
 	}
 	defer connSnmp.Conn.Close()
 
-	oids := []string{
-		".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.4",  // zxAnPonOnuIfRxPktRate
-		".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.47", // zxAnPonOnuIfTxPktRate
+	result, err := connSnmp.Get([]string{".1.3.6.1.2.1.1.3.0"})
+	if err != nil {
+		return 0, fmt.Errorf("error performing Get of sysUpTime: %w", err)
 	}
+	if len(result.Variables) == 0 || result.Variables[0].Type != g.TimeTicks {
+		return 0, fmt.Errorf("olt didnt return sysUpTime")
+	}
+	return g.ToBigInt(result.Variables[0].Value).Uint64(), nil
+}
 
-	for _, oid := range oids {
-		itemsRetrieved := 0
-		for itemsRetrieved < totalItems {
-			select {
-			case <-ctx.Done():
-				utils.Logline(fmt.Sprintf("timeout occurred while processing snmp zteOnusPkts itemsTotal (%d)", itemsRetrieved), host.Ip.String(), host.Name, ctx.Err())
-				return
-			default:
-				// Continue with the operation
-			}
-			result, err := connSnmp.GetBulk([]string{oid}, 0, connSnmp.MaxRepetitions)
-			if err != nil {
-				// connSnmp.Conn.Close() // close snmp connection if there's an error
-				utils.Logline("Error performing BulkWalk: ", host.Ip.String(), host.Name, "zteOnusPkts", oid, err)
-				return
-			}
+// loadOnuCounters reads the last samples of counters of the olt from estadistica.traffic_onu_counter, once per host
+func loadOnuCounters(db models.ConnDb, host models.HostInfo) error {
+	onuCountersPrev.Lock()
+	loaded := onuCountersPrev.loaded[host.Id]
+	onuCountersPrev.Unlock()
+	if loaded {
+		return nil
+	}
 
-			for _, value := range result.Variables {
-				// fmt.Printf("OID: %s, Type: %s, Value: %v\n", value.Name, value.Type, value.Value)
-				switch {
-				case strings.Contains(value.Name, ".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.4."):
-					results <- itemsTrafficResult{
-						snmpIndex: strings.ReplaceAll(value.Name, ".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.4.", ""),
-						valueData: fmt.Sprintf("%d", value.Value),
-						valueType: "RxPktRate",
-					}
-				case strings.Contains(value.Name, ".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.47."):
-					results <- itemsTrafficResult{
-						snmpIndex: strings.ReplaceAll(value.Name, ".1.3.6.1.4.1.3902.1082.500.4.2.2.2.1.47.", ""),
-						valueData: fmt.Sprintf("%d", value.Value),
-						valueType: "TxPktRate",
-					}
-				}
-				itemsRetrieved++
-				oid = value.Name // Update OID for the next GetBulk request
-			}
+	//create context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-			if uint32(len(result.Variables)) < connSnmp.MaxRepetitions || (!strings.Contains(oid, "3902.1082.500.4.2.2.2.1.4.") && !strings.Contains(oid, "3902.1082.500.4.2.2.2.1.47.")) {
-				break // No more items to retrieve
-			}
+	query := `SELECT snmp_index, sampled_at, olt_uptime::text, rx_octets::text, tx_octets::text, rx_pkts::text, tx_pkts::text
+		FROM estadistica.traffic_onu_counter
+		WHERE host_id=$1`
+	rows, err := db.Conn.Query(ctx, query, host.Id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	samples := map[string]onuCounters{}
+	for rows.Next() {
+		var snmpIndex, uptime, rxOctets, txOctets, rxPkts, txPkts string
+		var sampledAt time.Time
+		if err := rows.Scan(&snmpIndex, &sampledAt, &uptime, &rxOctets, &txOctets, &rxPkts, &txPkts); err != nil {
+			return err
 		}
+		samples[snmpIndex] = onuCounters{
+			sampledAt: sampledAt,
+			oltUptime: utils.StringToUint64(uptime),
+			rxOctets:  utils.StringToUint64(rxOctets),
+			txOctets:  utils.StringToUint64(txOctets),
+			rxPkts:    utils.StringToUint64(rxPkts),
+			txPkts:    utils.StringToUint64(txPkts),
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	connSnmp.Conn.Close()
+	//the samples taken while reading are newer than the persisted ones
+	onuCountersPrev.Lock()
+	defer onuCountersPrev.Unlock()
+	if current, ok := onuCountersPrev.samples[host.Id]; ok {
+		for snmpIndex, counters := range current {
+			samples[snmpIndex] = counters
+		}
+	}
+	onuCountersPrev.samples[host.Id] = samples
+	onuCountersPrev.loaded[host.Id] = true
+	return nil
 }
 
-func findItemBySn(items []itemsTrafficOnu, targetSnmpIndex string) *int {
-	for index, item := range items {
-		if item.snmpIndex == targetSnmpIndex {
-			return &index
+// saveOnuCounters writes the last samples of counters of the olt on estadistica.traffic_onu_counter,
+// the samples are copied under the lock so the other olts dont wait for the database
+func saveOnuCounters(db models.ConnDb, host models.HostInfo) error {
+	onuCountersPrev.Lock()
+	samples := make(map[string]onuCounters, len(onuCountersPrev.samples[host.Id]))
+	for snmpIndex, counters := range onuCountersPrev.samples[host.Id] {
+		samples[snmpIndex] = counters
+	}
+	onuCountersPrev.Unlock()
+	if len(samples) == 0 {
+		return nil
+	}
+
+	//create context
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	//open a transaction
+	tx, err := db.Conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO estadistica.traffic_onu_counter (host_id, snmp_index, sampled_at, olt_uptime, rx_octets, tx_octets, rx_pkts, tx_pkts)
+		VALUES ($1, $2, $3, $4::numeric, $5::numeric, $6::numeric, $7::numeric, $8::numeric)
+		ON CONFLICT (host_id, snmp_index) DO UPDATE SET sampled_at=EXCLUDED.sampled_at, olt_uptime=EXCLUDED.olt_uptime,
+			rx_octets=EXCLUDED.rx_octets, tx_octets=EXCLUDED.tx_octets, rx_pkts=EXCLUDED.rx_pkts, tx_pkts=EXCLUDED.tx_pkts`
+	for snmpIndex, counters := range samples {
+		_, err := tx.Exec(ctx, query, host.Id, snmpIndex, counters.sampledAt, strconv.FormatUint(counters.oltUptime, 10),
+			strconv.FormatUint(counters.rxOctets, 10), strconv.FormatUint(counters.txOctets, 10),
+			strconv.FormatUint(counters.rxPkts, 10), strconv.FormatUint(counters.txPkts, 10))
+		if err != nil {
+			return err
 		}
 	}
-	return nil
+
+	return tx.Commit(ctx)
 }
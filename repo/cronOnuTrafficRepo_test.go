@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCounterDelta(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    uint64
+		prev       uint64
+		counterMax uint64
+		want       uint64
+	}{
+		{"increment", 1500, 1000, math.MaxUint64, 500},
+		{"unchanged", 1000, 1000, math.MaxUint64, 0},
+		{"wrap 32 bits", 99, math.MaxUint32 - 100, math.MaxUint32, 200},
+		{"wrap 32 bits to 0", 0, math.MaxUint32, math.MaxUint32, 1},
+		{"wrap 64 bits", 9, math.MaxUint64 - 10, math.MaxUint64, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := counterDelta(tt.current, tt.prev, tt.counterMax); got != tt.want {
+				t.Errorf("counterDelta(%d, %d, %d) = %d, want %d", tt.current, tt.prev, tt.counterMax, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricTableCounterMax(t *testing.T) {
+	table := metricTable{
+		"1": {"onu-rx-octets": "10", "onu-rx-octets" + counterBitsKey: "32"},
+		"2": {"onu-rx-octets": "10", "onu-rx-octets" + counterBitsKey: "64"},
+		"3": {"onu-rx-octets": "10"},
+	}
+	tests := []struct {
+		index string
+		want  uint64
+	}{
+		{"1", math.MaxUint32},
+		{"2", math.MaxUint64},
+		{"3", math.MaxUint64},
+		{"4", math.MaxUint64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.index, func(t *testing.T) {
+			if got := table.counterMax(tt.index, "onu-rx-octets"); got != tt.want {
+				t.Errorf("counterMax(%q) = %d, want %d", tt.index, got, tt.want)
+			}
+		})
+	}
+}
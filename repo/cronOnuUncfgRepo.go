@@ -0,0 +1,155 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ired.com/olt/models"
+	"ired.com/olt/utils"
+)
+
+func CronOnuUncfg(db models.ConnDb, caller string) error {
+	//show status of worker
+	utils.Logline(utils.ShowStatusWorker(db, "onuUncfg", caller+"/begin"))
+
+	//get olts to work on
+	hostsInfo, err := getOltHosts(db.Ctx, db.Conn)
+	if err != nil {
+		utils.Logline("error getting host to run cron", err)
+		return err
+	}
+
+	//iterate over hosts and create one goroutine for every olt
+	var wg sync.WaitGroup
+	for _, host := range hostsInfo {
+		wg.Add(1)
+		go workerOnuUncfg(&wg, db, host)
+	}
+
+	wg.Wait()
+
+	//show status of worker
+	utils.Logline(utils.ShowStatusWorker(db, "onuUncfg", caller+"/ending"))
+
+	return nil
+}
+
+func workerOnuUncfg(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo) {
+	defer wg.Done()
+
+	defer func() {
+		// recover from panic if one occured. Set err to nil otherwise.
+		if recover() != nil {
+			utils.Logline("error on this subprocess - workerOnuUncfg", host.Ip.String(), host.Name)
+			return
+		}
+	}()
+
+	driver, err := getDriver(host.Vendor)
+	if err != nil {
+		utils.Logline("error getting driver", host.Ip.String(), host.Name, err)
+		return
+	}
+
+	// Create a context with a timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	//crear canal para recibir la respuesta de las operaciones en telnet
+	errChan := make(chan error, 1)
+	resultChan := make(chan []uncfgOnu, 1)
+
+	go func() {
+		onus, err := driver.UncfgOnus(host)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		resultChan <- onus
+	}()
+
+	//wait for response on errChan or resultChan
+	select {
+	case <-ctx.Done():
+		// Timeout occurred
+		utils.Logline("timeout occurred while processing telnet on", host.Ip.String(), host.Name, ctx.Err())
+		return
+	case err := <-errChan:
+		if errors.Is(err, errDriverUnsupported) {
+			utils.Logline(host.Vendor+" worker on construction", host.Ip.String(), host.Name)
+			return
+		}
+		utils.Logline("error processing telnet on", host.Ip.String(), host.Name, err)
+		return
+	case onus := <-resultChan:
+		if err := saveUncfgOnus(db, host, onus); err != nil {
+			utils.Logline("error saving unconfigured onus", host.Ip.String(), host.Name, err)
+			return
+		}
+	}
+}
+
+// saveUncfgOnus updates last_seen of the onus already seen and inserts the new ones on network.onu_uncfg
+func saveUncfgOnus(db models.ConnDb, host models.HostInfo, onus []uncfgOnu) error {
+	//create context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	//open a transaction
+	tx, err := db.Conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `INSERT INTO network.onu_uncfg (host_id, port, sn, first_seen, last_seen)
+		VALUES ($1, $2, $3, now(), now())
+		ON CONFLICT (host_id, sn) DO UPDATE SET port=EXCLUDED.port, last_seen=EXCLUDED.last_seen`
+	for _, onu := range onus {
+		if _, err := tx.Exec(ctx, query, host.Id, onu.Port, onu.Sn); err != nil {
+			return err
+		}
+	}
+
+	//commit transaction
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	utils.Logline(fmt.Sprintf("(%d) unconfigured onus", len(onus)), host.Ip.String(), host.Name, "get_onu_uncfg")
+
+	return nil
+}
+
+// GetUncfgOnus returns the unconfigured onus seen on the last minutes, filtered by olt and sn if they are not empty
+func GetUncfgOnus(db models.ConnDb, hostId string, sn string, minutes int) ([]models.UncfgOnu, error) {
+	query := `SELECT h.id, h.nombre, host(h.ip), u.port, u.sn, u.first_seen::text, u.last_seen::text
+		FROM network.onu_uncfg as u
+		JOIN network.host as h ON h.id=u.host_id
+		WHERE u.last_seen >= now() - make_interval(mins => $1)
+			AND ($2='' OR h.id::text=$2) AND ($3='' OR u.sn ILIKE '%' || $3 || '%')
+		ORDER BY h.ip ASC, u.port ASC, u.sn ASC`
+	rows, err := db.Conn.Query(db.Ctx, query, minutes, hostId, sn)
+	if err != nil {
+		utils.Logline("error getting unconfigured onus", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	onus := []models.UncfgOnu{}
+	for rows.Next() {
+		var onu models.UncfgOnu
+		err = rows.Scan(&onu.HostId, &onu.Name, &onu.Ip, &onu.Port, &onu.Sn, &onu.FirstSeen, &onu.LastSeen)
+		if err != nil {
+			utils.Logline("error scanning rows of unconfigured onus", err)
+			return nil, err
+		}
+		onus = append(onus, onu)
+	}
+	rows.Close()
+
+	return onus, nil
+}
@@ -0,0 +1,198 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"ired.com/olt/models"
+	"ired.com/olt/utils"
+)
+
+// counters of one port of the olt from IF-MIB
+type portCounters struct {
+	sampledAt time.Time
+	oltUptime uint64
+	inOctets  uint64
+	outOctets uint64
+	inErrors  uint64
+	outErrors uint64
+}
+
+// last sample of counters of every port keyed by hostId/ifIndex, needed to calculate the rates on the next run
+var portCountersPrev = struct {
+	sync.Mutex
+	samples map[string]portCounters
+}{samples: map[string]portCounters{}}
+
+// interfaces that are not pon ports or uplinks
+var rePortSkip = regexp.MustCompile(`^(lo|loopback|null|vlan|vlanif|mng|mgmt|console|cpu|sit|eth0|inner)`)
+
+var portMetrics = []string{"port-name", "port-oper-status", "port-speed", "port-in-octets", "port-out-octets", "port-in-errors", "port-out-errors"}
+
+func CronPortTraffic(db models.ConnDb, caller string) error {
+	//show status of worker
+	utils.Logline(utils.ShowStatusWorker(db, "portTraffic", caller+"/begin"))
+
+	//get hostItems of the ports and storeIt in a slice
+	query := `SELECT h.id, hi.id as item_id, hi.sn as sn, hi.nombre
+		FROM network.host as h
+		LEFT JOIN network.host_item as hi ON hi.host_id=h.id
+		WHERE h.info->>'telnet_username' IS NOT NULL AND ` + hostHasSnmpSql + ` AND h.activo=true AND hi.nombre LIKE 'olt-port-%'
+		ORDER BY h.ip ASC, hi.nombre ASC`
+	rows, err := db.Conn.Query(db.Ctx, query)
+	if err != nil {
+		utils.Logline("error getting host - items from olts", err)
+		return err
+	}
+	defer rows.Close()
+
+	var items []hostItems
+	for rows.Next() {
+		var item hostItems
+		err = rows.Scan(&item.HostId, &item.ItemId, &item.ItemSn, &item.ItemNombre)
+		if err != nil {
+			utils.Logline("error scanning rows of host_items from olts", err)
+			return err
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+
+	//get olts to work on
+	hostsInfo, err := getOltHosts(db.Ctx, db.Conn)
+	if err != nil {
+		utils.Logline("error getting host to run cron", err)
+		return err
+	}
+
+	//iterate over hosts and create one goroutine for every olt
+	var wg sync.WaitGroup
+	for _, host := range hostsInfo {
+		wg.Add(1)
+		go workerPortTraffic(&wg, db, host, items)
+	}
+
+	wg.Wait()
+
+	//show status of worker
+	utils.Logline(utils.ShowStatusWorker(db, "portTraffic", caller+"/ending"))
+
+	return nil
+}
+
+func workerPortTraffic(wg *sync.WaitGroup, db models.ConnDb, host models.HostInfo, items []hostItems) {
+	defer wg.Done()
+
+	defer func() {
+		// recover from panic if one occured. Set err to nil otherwise.
+		if recover() != nil {
+			utils.Logline("error on this subprocess - workerPortTraffic", host.Ip.String(), host.Name)
+			return
+		}
+	}()
+
+	// Create a context with a timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Second)
+	defer cancel()
+
+	//crear canal para recibir la respuesta de las operaciones en snmp
+	errChan := make(chan error, 1)
+	resultChan := make(chan []oltMetric, 1)
+
+	go func() {
+		metrics, err := portTraffic(host)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		resultChan <- metrics
+	}()
+
+	//wait for response on errChan or resultChan
+	select {
+	case <-ctx.Done():
+		// Timeout occurred
+		utils.Logline("timeout occurred while processing snmp on", host.Ip.String(), host.Name, ctx.Err())
+		return
+	case err := <-errChan:
+		utils.Logline("error processing snmp on", host.Ip.String(), host.Name, err)
+		return
+	case metrics := <-resultChan:
+		response := oltMetricsToItems(db, host, items, metrics)
+		if err := insertEstadistica(db, response, host.Ip.String(), "get_port_traffic"); err != nil {
+			utils.Logline("error inserting data", host.Ip.String(), host.Name, err)
+			return
+		}
+	}
+}
+
+// portTraffic walks IF-MIB on the olt and returns per port: status, kbps in and out, utilisation in percent of the speed
+// and errors in and out since the previous run. the rates need a previous sample, so on the first run only the status is returned
+func portTraffic(host models.HostInfo) ([]oltMetric, error) {
+	uptime, err := oltUptime(host)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := fetchMetricTable(host, "ifmib", portMetrics)
+	if err != nil {
+		return nil, err
+	}
+	sampledAt := time.Now()
+
+	portCountersPrev.Lock()
+	defer portCountersPrev.Unlock()
+
+	var result []oltMetric
+	for ifIndex, row := range table {
+		name, ok := row["port-name"]
+		if !ok || rePortSkip.MatchString(name) {
+			continue
+		}
+		if _, ok := row["port-in-octets"]; !ok {
+			continue // without 64 bits counters
+		}
+
+		result = append(result, oltMetric{Sn: "port-status." + ifIndex, Name: "olt-port-status-" + name, Value: row["port-oper-status"], Table: "detalle_int"})
+
+		counters := portCounters{
+			sampledAt: sampledAt,
+			oltUptime: uptime,
+			inOctets:  utils.StringToUint64(row["port-in-octets"]),
+			outOctets: utils.StringToUint64(row["port-out-octets"]),
+			inErrors:  utils.StringToUint64(row["port-in-errors"]),
+			outErrors: utils.StringToUint64(row["port-out-errors"]),
+		}
+
+		key := host.Id + "/" + ifIndex
+		prev, ok := portCountersPrev.samples[key]
+		portCountersPrev.samples[key] = counters
+		if !ok || counters.oltUptime < prev.oltUptime {
+			continue
+		}
+		seconds := counters.sampledAt.Sub(prev.sampledAt).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+
+		kbIn := utils.BytesToKb(fmt.Sprintf("%f", float64(counterDelta(counters.inOctets, prev.inOctets, table.counterMax(ifIndex, "port-in-octets")))/seconds))
+		kbOut := utils.BytesToKb(fmt.Sprintf("%f", float64(counterDelta(counters.outOctets, prev.outOctets, table.counterMax(ifIndex, "port-out-octets")))/seconds))
+		result = append(result,
+			oltMetric{Sn: "port-kbin." + ifIndex, Name: "olt-port-kbin-" + name, Value: utils.Int64ToString(kbIn), Table: "detalle_int"},
+			oltMetric{Sn: "port-kbout." + ifIndex, Name: "olt-port-kbout-" + name, Value: utils.Int64ToString(kbOut), Table: "detalle_int"},
+			oltMetric{Sn: "port-errin." + ifIndex, Name: "olt-port-errin-" + name, Value: fmt.Sprintf("%d", counterDelta(counters.inErrors, prev.inErrors, table.counterMax(ifIndex, "port-in-errors"))), Table: "detalle_int"},
+			oltMetric{Sn: "port-errout." + ifIndex, Name: "olt-port-errout-" + name, Value: fmt.Sprintf("%d", counterDelta(counters.outErrors, prev.outErrors, table.counterMax(ifIndex, "port-out-errors"))), Table: "detalle_int"},
+		)
+
+		//utilisation of the busiest direction, ifHighSpeed is in Mbps
+		if speed, ok := row["port-speed"]; ok && utils.StringToInt64(speed) > 0 {
+			util := utils.FloatToInt64(float64(max(kbIn, kbOut)) * 100 / float64(utils.StringToInt64(speed)*1000))
+			result = append(result, oltMetric{Sn: "port-util." + ifIndex, Name: "olt-port-util-" + name, Value: utils.Int64ToString(util), Table: "detalle_int"})
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,224 @@
+package repo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"ired.com/olt/models"
+	"ired.com/olt/utils"
+)
+
+type cdataDriver struct{}
+
+func init() {
+	registerDriver("cdata", cdataDriver{})
+}
+
+func (cdataDriver) Clock(host models.HostInfo) (string, time.Time, error) {
+	// Connect to the OLT
+	conn, err := cliConnect("cdata", host)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//send command and read response
+	response, err := cliRun(conn, host, "clock", nil, 2*time.Second)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	conn.Close()
+
+	//clean response and parse it
+	response = utils.OltCleanOutput(response)
+	for _, line := range strings.Split(response, "\n") {
+		if !strings.Contains(line, "show time") {
+			response = strings.TrimSpace(line)
+		}
+	}
+
+	// Parse the string into a time.Time object
+	loc, err := time.LoadLocation("America/Caracas")
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error loading location: %w", err)
+	}
+
+	// Define the layout for parsing
+	layout := "2006-01-02 15:04:05"
+	t, err := time.ParseInLocation(layout, response[:19], loc)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error parsing date: %w", err)
+	}
+
+	return response, t, nil
+}
+
+func (cdataDriver) ChassisInfo(host models.HostInfo) ([]oltMetric, error) {
+	var result []oltMetric
+
+	// Connect to the OLT via telnet to get temp, cpu and fan
+	connTelnet, err := cliConnect("cdata", host)
+	if err != nil {
+		return nil, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer connTelnet.Close()
+
+	//send command and read response - get temperature
+	response, err := cliRun(connTelnet, host, "temperature", nil, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	response = utils.OltCleanOutput(response)
+	for _, line := range strings.Split(response, "\n") {
+		if strings.Contains(line, "board") {
+			response = strings.ReplaceAll(line, "the temperature of the board:", "")
+			response = strings.TrimSpace(strings.ReplaceAll(response, "(c)", ""))
+
+			if response != "" {
+				//use oid of zte olt
+				result = append(result, oltMetric{Sn: ".1.3.6.1.4.1.3902.1082.10.10.2.1.5.1.3.1.1", Name: "olt-temperature", Value: response, Table: "detalle_int"})
+			}
+		}
+	}
+
+	//send command and read response - get cpu usage 1min
+	if response, err = cliRun(connTelnet, host, "cpu", nil, 2*time.Second); err != nil {
+		return nil, err
+	}
+	response = utils.OltCleanOutput(response)
+	for _, line := range strings.Split(response, "\n") {
+		if strings.Contains(line, "1min") {
+			response = strings.ReplaceAll(line, "load average(1min)", "")
+			response = strings.TrimSpace(strings.ReplaceAll(response, ":", ""))
+
+			if response != "" {
+				//use oid of zte olt
+				result = append(result, oltMetric{Sn: ".1.9.1.1.1", Name: "olt-card-cpuload-1", Value: response, Table: "detalle_int"})
+			}
+		}
+	}
+
+	//send command and read response - get fan
+	if response, err = cliRun(connTelnet, host, "fan", nil, 2*time.Second); err != nil {
+		return nil, err
+	}
+	response = utils.OltCleanOutput(response)
+	for _, line := range strings.Split(response, "\n") {
+		i := 1
+		if strings.Contains(line, "status") {
+			re := regexp.MustCompile(`\((\d+)rpm\)`)
+			match := re.FindStringSubmatch(line)
+			if len(match) > 1 {
+				snItem := fmt.Sprintf(".7.1.1.%d", i) //use oid of zte olt
+				nombreItem := fmt.Sprintf("olt-fan-%d", i)
+				result = append(result, oltMetric{Sn: snItem, Name: nombreItem, Value: match[1], Table: "detalle_int"})
+			}
+			i++
+		}
+	}
+
+	connTelnet.Close()
+
+	//connect to snmp
+	connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.Snmp, 4, 4, true)
+	if err != nil {
+		return nil, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer connSnmp.Conn.Close()
+
+	//get modelo and uptime via snmp
+	oids := []string{
+		".1.3.6.1.4.1.17409.2.3.1.2.1.1.3.1", //modelo
+		".1.3.6.1.4.1.17409.2.3.1.2.1.1.5.1", //uptime
+	}
+	resultSnmp, err := connSnmp.Get(oids)
+	if err != nil {
+		return nil, fmt.Errorf("error performing Get for OIDs %v: %w", oids, err)
+	}
+	for _, value := range resultSnmp.Variables {
+		//change the oid to use in DB the same as the OLT ZTEs
+		switch value.Name {
+		case ".1.3.6.1.4.1.17409.2.3.1.2.1.1.3.1":
+			modelo := "cdata " + strings.ToLower(fmt.Sprintf("%s", value.Value))
+			result = append(result, oltMetric{Sn: ".1.3.6.1.2.1.1.1.0", Name: "olt-devmodel", Value: modelo, Table: "detalle_text"})
+		default:
+			uptime := fmt.Sprintf("%d", value.Value)
+			result = append(result, oltMetric{Sn: ".1.3.6.1.2.1.1.3.0", Name: "olt-uptime", Value: uptime, Table: "detalle_int"})
+		}
+	}
+
+	connSnmp.Conn.Close()
+
+	return result, nil
+}
+
+// the gpon olts of cdata use the tables under 17409.2.8 and the epon olts the ones of NSCRTV-EPONEOC under 17409.2.3,
+// the catalog walks the epon table as fallback when the gpon one is empty
+
+// cdata only exposes the counters of the onus, the rates are calculated between runs
+func (cdataDriver) OnuTable(host models.HostInfo, names []string) (metricTable, error) {
+	return fetchMetricTable(host, "cdata", names)
+}
+
+func (cdataDriver) OnuTraffic(host models.HostInfo) ([]itemsTrafficOnu, error) {
+	return onuTrafficFromCounters(host, "cdata")
+}
+
+func (cdataDriver) SaveConfig(host models.HostInfo) error {
+	// Connect to the OLT, the session is left on config mode
+	conn, err := cliConnect("cdata", host)
+	if err != nil {
+		return fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//send command and read response
+	response, err := cliRun(conn, host, "save_config", nil, 55*time.Second)
+	if err != nil {
+		return err
+	}
+	conn.Close()
+
+	//the olt prints the error on the same session, the write is only confirmed if there is none
+	if err := saveConfigFailed(response); err != nil {
+		return fmt.Errorf("error proccesing 'save': %w", err)
+	}
+
+	return nil
+}
+
+func (cdataDriver) UncfgOnus(host models.HostInfo) ([]uncfgOnu, error) {
+	// Connect to the OLT
+	conn, err := cliConnect("cdata", host)
+	if err != nil {
+		return nil, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//send command and read response
+	response, err := cliRun(conn, host, "uncfg_onus", nil, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	conn.Close()
+
+	return parseUncfgOnus(response), nil
+}
+
+func (cdataDriver) ProvisionOnu(host models.HostInfo, onu models.OnuProvision) (int, error) {
+	return 0, errDriverUnsupported
+}
+
+func (cdataDriver) DeprovisionOnu(host models.HostInfo, sn string) (string, int, error) {
+	return "", 0, errDriverUnsupported
+}
+
+func (cdataDriver) OnuAction(host models.HostInfo, sn string, action string) (string, error) {
+	return "", errDriverUnsupported
+}
+
+func (cdataDriver) OnuBandwidth(host models.HostInfo, sn string, upProfile string, downProfile string) error {
+	return errDriverUnsupported
+}
@@ -0,0 +1,367 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"ired.com/olt/catalog"
+	"ired.com/olt/clitemplate"
+	"ired.com/olt/models"
+	"ired.com/olt/utils"
+)
+
+// Driver is implemented once per olt vendor, every method only talks to the device and returns raw values,
+// creating the network.host_item rows and inserting into estadistica is handled by the cron repos
+type Driver interface {
+	// date of the olt as printed on the cli and parsed in America/Caracas
+	Clock(host models.HostInfo) (string, time.Time, error)
+	// cards, cpu, fans, temperature, uptime and model of the chassis
+	ChassisInfo(host models.HostInfo) ([]oltMetric, error)
+	// metrics of the catalog of every onu registered on the olt read in one pass (onu-name, onu-status, onu-sn, onu-rx in dbm
+	// and the optics of the onu side onu-tx, onu-rxonu, onu-temperature, onu-voltage, onu-bias),
+	// keyed by snmpIndex and by the name of the metric. with an error the table can still have a partial result
+	OnuTable(host models.HostInfo, names []string) (metricTable, error)
+	// kbps and pps per onu ready to insert on estadistica.traffic_onu
+	OnuTraffic(host models.HostInfo) ([]itemsTrafficOnu, error)
+	// write the running config to the startup config
+	SaveConfig(host models.HostInfo) error
+	// onus connected to the pon ports but not authorized on the olt
+	UncfgOnus(host models.HostInfo) ([]uncfgOnu, error)
+	// authorize the onu on the first free id of the pon port, configure its service and return the id
+	ProvisionOnu(host models.HostInfo, onu models.OnuProvision) (int, error)
+	// remove the onu with the gpon sn from the olt and return the pon port and id it had
+	DeprovisionOnu(host models.HostInfo, sn string) (string, int, error)
+	// run the action (reboot, disable, enable or reset) on the onu with the gpon sn and return the output of the olt
+	OnuAction(host models.HostInfo, sn string, action string) (string, error)
+	// apply the profiles of bandwidth to the onu with the gpon sn and verify them reading the config back
+	OnuBandwidth(host models.HostInfo, sn string, upProfile string, downProfile string) error
+}
+
+// value read from the olt for the host_item identified by Sn, the item is created with Name if missing
+type oltMetric struct {
+	Sn    string
+	Name  string
+	Value string
+	Table string
+}
+
+// onu seen on the pon port but not authorized
+type uncfgOnu struct {
+	Port string
+	Sn   string
+}
+
+// value read from the olt for one onu
+type onuValue struct {
+	snmpIndex string
+	value     string
+}
+
+// values of the metrics of the catalog keyed by snmpIndex and by the name of the metric
+type metricTable map[string]map[string]string
+
+// column returns the values of the metric on every row
+func (t metricTable) column(name string) []onuValue {
+	var values []onuValue
+	for snmpIndex, row := range t {
+		if value, ok := row[name]; ok {
+			values = append(values, onuValue{snmpIndex: snmpIndex, value: value})
+		}
+	}
+	return values
+}
+
+// suffix of the key of the row with the width of a counter, taken from the catalog or the snmp type of the value
+const counterBitsKey = "#bits"
+
+// counterMax returns the max value of the counter of the row before it wraps to 0
+func (t metricTable) counterMax(snmpIndex string, name string) uint64 {
+	bits, _ := strconv.Atoi(t[snmpIndex][name+counterBitsKey])
+	return catalog.CounterMax(bits)
+}
+
+// returned by a driver for a task the vendor doesnt support yet
+var errDriverUnsupported = errors.New("task not supported by driver")
+
+// vendor of the olt configured by hand, uses the vendor key of host info and falls back to the telnet username used before the key existed.
+// the one found by the task olt_discovery is only reported as a mismatch, a wrong detection cant send the commands of another vendor
+const hostVendorSql = `COALESCE(h.info->>'vendor', CASE WHEN h.info->>'telnet_username' IN ('vsol', 'cdata') THEN h.info->>'telnet_username' ELSE 'zte' END)`
+
+// configured model, vendor and model found by the task olt_discovery of the olt, the model is picked by hostModel
+const hostModelSql = `COALESCE(h.info->>'model', ''), COALESCE(h.info->>'detected_vendor', ''), COALESCE(h.info->>'detected_model', '')`
+
+// hostModel returns the detected model only when it was detected on the configured vendor, the model of another vendor
+// would select its templates. otherwise the configured model, empty if it isnt set
+func hostModel(vendor string, model string, detectedVendor string, detectedModel string) string {
+	if detectedModel != "" && detectedVendor == vendor {
+		return detectedModel
+	}
+	return model
+}
+
+// hostQuerier is the part of the pool used to read the olts
+type hostQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+var drivers = map[string]Driver{}
+
+// registerDriver is called from the init of every vendor file
+func registerDriver(vendor string, driver Driver) {
+	drivers[vendor] = driver
+}
+
+// getDriver returns the driver registered for the vendor
+func getDriver(vendor string) (Driver, error) {
+	driver, ok := drivers[vendor]
+	if !ok {
+		return nil, fmt.Errorf("there is no driver registered for vendor '%s'", vendor)
+	}
+	return driver, nil
+}
+
+// snmp credentials of the olt, snmpv3 is used when snmp_v3_user is set
+const hostSnmpSql = `COALESCE(h.info->>'snmp_read_community', ''), COALESCE(h.info->>'snmp_v3_user', ''),
+	COALESCE(h.info->>'snmp_v3_auth_protocol', 'sha'), COALESCE(h.info->>'snmp_v3_auth_password', ''),
+	COALESCE(h.info->>'snmp_v3_priv_protocol', 'aes'), COALESCE(h.info->>'snmp_v3_priv_password', ''),
+	COALESCE(h.info->>'snmp_trap_community', h.info->>'snmp_read_community', '')`
+
+// the olt has snmp configured, v2c or v3
+const hostHasSnmpSql = `(h.info->>'snmp_read_community' IS NOT NULL OR h.info->>'snmp_v3_user' IS NOT NULL)`
+
+// cli protocol of the olt, telnet if it is not set on network.host.info
+const hostCliProtocolSql = `COALESCE(h.info->>'cli_protocol', 'telnet')`
+
+// cliPort returns the port of the cli session of the olt, it can be changed with cli_port on network.host.info
+func cliPort(host models.HostInfo) string {
+	if host.CliPort != "" {
+		return host.CliPort
+	}
+	if host.CliProtocol == "ssh" {
+		return "22"
+	}
+	return "23"
+}
+
+// cliConnect opens the cli session with the olt using the login script of vendor
+func cliConnect(vendor string, host models.HostInfo) (*utils.CliSession, error) {
+	return utils.OltCliConnect(vendor, host.CliProtocol, host.Ip.String(), cliPort(host), host.TelnetUsername, host.TelnetPasswd, host.Ssh)
+}
+
+// question of the olts before a reboot or a reset of an onu
+const cliConfirm = `(?i)\[yes/no\]\s*:?\s*$`
+
+// cliRun renders the cli template name for the vendor and model of the olt and sends its commands one by one, the errors
+// printed by the olt stop the batch. the olts ask for confirmation on some commands, it is answered with yes
+func cliRun(conn *utils.CliSession, host models.HostInfo, name string, data map[string]any, timeout time.Duration) (string, error) {
+	commands, err := clitemplate.Render(host.Vendor, host.Model, name, data)
+	if err != nil {
+		return "", err
+	}
+
+	var output []string
+	for _, command := range commands {
+		response, err := conn.SendConfirm(command, cliConfirm, "yes", timeout)
+		if err != nil {
+			return strings.Join(output, "\n"), fmt.Errorf("error proccesing '%s': %w", command, err)
+		}
+		output = append(output, response)
+	}
+
+	return strings.Join(output, "\n"), nil
+}
+
+// getOltHosts returns the active olts with telnet and snmp configured
+func getOltHosts(ctx context.Context, conn *pgxpool.Pool) ([]models.HostInfo, error) {
+	return queryOltHosts(ctx, conn, `ORDER BY RANDOM()`)
+}
+
+// getOltHost returns the active olt with id hostId
+func getOltHost(ctx context.Context, conn *pgxpool.Pool, hostId string) (models.HostInfo, error) {
+	hostsInfo, err := queryOltHosts(ctx, conn, `AND h.id::text=$1`, hostId)
+	if err != nil {
+		return models.HostInfo{}, err
+	}
+	if len(hostsInfo) == 0 {
+		return models.HostInfo{}, fmt.Errorf("there is no active olt with id %s", hostId)
+	}
+	return hostsInfo[0], nil
+}
+
+// queryOltHosts runs the query of the olts with filter added after the WHERE
+func queryOltHosts(ctx context.Context, conn hostQuerier, filter string, args ...any) ([]models.HostInfo, error) {
+	query := `SELECT h.id, h.ip, h.nombre, h.info->>'telnet_username' as username, h.info->>'telnet_password' as password, ` + hostSnmpSql + `, ` + hostVendorSql + ` as vendor, ` + hostModelSql + `,
+			` + hostCliProtocolSql + ` as cli_protocol, COALESCE(h.info->>'cli_port', '') as cli_port, COALESCE(h.info->>'onu_traffic_mode', 'rate') as traffic_mode,
+			COALESCE(h.info->>'ssh_host_key', '') as ssh_host_key, COALESCE(h.info->>'ssh_legacy', '') IN ('true', '1') as ssh_legacy
+		FROM network.host as h
+		WHERE h.info->>'telnet_username' IS NOT NULL AND ` + hostHasSnmpSql + ` AND h.activo=true
+		` + filter
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	//create slice of hosts
+	var hostsInfo []models.HostInfo
+	for rows.Next() {
+		var host models.HostInfo
+		var detectedVendor, detectedModel string
+		err = rows.Scan(&host.Id, &host.Ip, &host.Name, &host.TelnetUsername, &host.TelnetPasswd,
+			&host.Snmp.Community, &host.Snmp.User, &host.Snmp.AuthProtocol, &host.Snmp.AuthPassword, &host.Snmp.PrivProtocol, &host.Snmp.PrivPassword, &host.Snmp.TrapCommunity,
+			&host.Vendor, &host.Model, &detectedVendor, &detectedModel, &host.CliProtocol, &host.CliPort, &host.TrafficMode,
+			&host.Ssh.HostKey, &host.Ssh.Legacy)
+		if err != nil {
+			utils.Logline("error scanning rows of host olts", err)
+			return nil, err
+		}
+		host.Model = hostModel(host.Vendor, host.Model, detectedVendor, detectedModel)
+		hostsInfo = append(hostsInfo, host)
+	}
+	rows.Close()
+
+	return hostsInfo, nil
+}
+
+// saveConfigFailed checks the response of the save command of the olt
+func saveConfigFailed(response string) error {
+	lower := strings.ToLower(response)
+	if strings.Contains(lower, "error") || strings.Contains(lower, "fail") || strings.Contains(lower, "invalid") {
+		return fmt.Errorf("save config not confirmed: %s", utils.OltCleanOutput(response))
+	}
+	return nil
+}
+
+// port and gpon sn on the same line of the list of unconfigured onus, used by the vendors without a fixed layout
+var reUncfgOnu = regexp.MustCompile(`(\d+/\d+(?:/\d+)?)\S*\s+(?:.*?\s)?([a-z]{4}[0-9a-f]{8})\b`)
+
+// parseUncfgOnus finds the port and sn of every unconfigured onu on the output of the cli
+func parseUncfgOnus(response string) []uncfgOnu {
+	var onus []uncfgOnu
+	for _, line := range strings.Split(utils.OltCleanOutput(response), "\n") {
+		if match := reUncfgOnu.FindStringSubmatch(line); len(match) > 2 {
+			onus = append(onus, uncfgOnu{Port: match[1], Sn: strings.ToUpper(match[2])})
+		}
+	}
+	return onus
+}
+
+// hasMetric reports if the metric is declared on the catalog for the vendor and model of the olt
+func hasMetric(host models.HostInfo, name string) bool {
+	_, err := catalog.Lookup(host.Vendor, host.Model, name)
+	return err == nil
+}
+
+// max time to read the optics of the pon ports, they run on their own worker apart from the chassis info
+const ponOpticsTimeout = 30 * time.Second
+
+// ponOptics reads tx power, temperature, voltage and bias of the transceivers of the pon ports,
+// with an error the values read until then are returned
+func ponOptics(host models.HostInfo) ([]oltMetric, error) {
+	names := []string{"pon-tx-power", "pon-temperature", "pon-voltage", "pon-bias"}
+	table, err := fetchMetricTableWithin(host, host.Vendor, names, ponOpticsTimeout)
+
+	var result []oltMetric
+	for _, name := range names {
+		metric, err := catalog.Lookup(host.Vendor, host.Model, name)
+		if err != nil {
+			continue
+		}
+		for _, value := range table.column(name) {
+			result = append(result, oltMetric{Sn: name + "." + value.snmpIndex, Name: metric.ItemName(value.snmpIndex), Value: value.value, Table: metric.Table})
+		}
+	}
+	return result, err
+}
+
+// fetchMetricTable reads the columns of the metrics of the catalog with GetBulk requests of several columns and joins them by index,
+// if every column is empty the fallbacks of the metrics are read instead. with an error the table has the rows read until then
+func fetchMetricTable(host models.HostInfo, vendor string, names []string) (metricTable, error) {
+	return fetchMetricTableWithin(host, vendor, names, 45*time.Second)
+}
+
+// fetchMetricTableWithin is fetchMetricTable with the max time of every walk, the fallbacks included
+func fetchMetricTableWithin(host models.HostInfo, vendor string, names []string, timeout time.Duration) (metricTable, error) {
+	//create context
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var metrics []catalog.Metric
+	for _, name := range names {
+		metric, err := catalog.Lookup(vendor, host.Model, name)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, metric)
+	}
+
+	table, err := walkMetricTable(ctx, host, names, metrics)
+	if len(table) > 0 || err != nil {
+		return table, err
+	}
+
+	//try with the fallbacks, the values keep the names of the original metrics
+	var fallback bool
+	for i, metric := range metrics {
+		if metric.Fallback == "" {
+			continue
+		}
+		if metrics[i], err = catalog.Lookup(vendor, host.Model, metric.Fallback); err != nil {
+			return nil, err
+		}
+		fallback = true
+	}
+	if !fallback {
+		return table, nil
+	}
+	return walkMetricTable(ctx, host, names, metrics)
+}
+
+func walkMetricTable(ctx context.Context, host models.HostInfo, names []string, metrics []catalog.Metric) (metricTable, error) {
+	//the same oid can be used by several metrics, but it is requested once
+	var columns []string
+	requested := map[string]bool{}
+	for _, metric := range metrics {
+		if !requested[metric.Oid] {
+			requested[metric.Oid] = true
+			columns = append(columns, metric.Oid)
+		}
+	}
+
+	//connect to snmp, the rows of every page are multiplied by the columns
+	connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.Snmp, len(columns), 10, false)
+	if err != nil {
+		return nil, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer connSnmp.Conn.Close()
+
+	rows, err := utils.SnmpTable(ctx, connSnmp, columns, 2)
+
+	table := metricTable{}
+	for _, pdus := range rows {
+		for i, metric := range metrics {
+			value, ok := pdus[strings.TrimSuffix(metric.Oid, ".")]
+			if !ok {
+				continue
+			}
+			if row, ok := metric.ParseRow(value); ok {
+				if table[row.Index] == nil {
+					table[row.Index] = map[string]string{}
+				}
+				table[row.Index][names[i]] = row.Value
+				if metric.Type == "counter" {
+					table[row.Index][names[i]+counterBitsKey] = strconv.Itoa(metric.CounterBits(value))
+				}
+			}
+		}
+	}
+
+	return table, err
+}
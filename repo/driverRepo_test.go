@@ -0,0 +1,119 @@
+package repo
+
+import (
+	"context"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestParseUncfgOnus(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []uncfgOnu
+	}{
+		{
+			name: "zte c300",
+			response: "show gpon onu uncfg\r\n" +
+				"OnuIndex                 Sn                  State\r\n" +
+				"---------------------------------------------------------------------\r\n" +
+				"gpon-onu_1/2/1:1         ZTEGC8A1B2C3        unknown\r\n" +
+				"gpon-onu_1/3/16:1        HWTC1A2B3C4D        unknown\r\n" +
+				"ZXAN#",
+			want: []uncfgOnu{{Port: "1/2/1", Sn: "ZTEGC8A1B2C3"}, {Port: "1/3/16", Sn: "HWTC1A2B3C4D"}},
+		},
+		{
+			name: "vsol",
+			response: "show onu auto-find\r\n" +
+				"OnuIndex    Sn              State\r\n" +
+				"GPON0/1:1   VSOL0012AB34    unknow\r\n" +
+				"OLT(config)#",
+			want: []uncfgOnu{{Port: "0/1", Sn: "VSOL0012AB34"}},
+		},
+		{
+			name: "cdata",
+			response: "show ont autofind all\r\n" +
+				"F/S/P   ONT   SN             Password   LOID\r\n" +
+				"0/0/1   1     XPON11223344   N/A        N/A\r\n" +
+				"0/0/2   1     DDSL5566aabb   N/A        N/A\r\n" +
+				"OLT(config)#",
+			want: []uncfgOnu{{Port: "0/0/1", Sn: "XPON11223344"}, {Port: "0/0/2", Sn: "DDSL5566AABB"}},
+		},
+		{
+			name:     "without onus",
+			response: "show gpon onu uncfg\r\n%Code 32310-GPONSRV : No related information to show.\r\nZXAN#",
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseUncfgOnus(tt.response); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseUncfgOnus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeHostRows returns every row with the columns of queryOltHosts in order, the methods not used by it arent implemented
+type fakeHostRows struct {
+	pgx.Rows
+	rows [][]any
+	next int
+}
+
+func (r *fakeHostRows) Next() bool {
+	r.next++
+	return r.next <= len(r.rows)
+}
+
+func (r *fakeHostRows) Scan(dest ...any) error {
+	for i, value := range r.rows[r.next-1] {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func (r *fakeHostRows) Close() {}
+
+func (r *fakeHostRows) Err() error { return nil }
+
+type fakeHostQuerier [][]any
+
+func (q fakeHostQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return &fakeHostRows{rows: q}, nil
+}
+
+// fakeHostRow is a row of an olt with the vendor and model configured and the ones detected
+func fakeHostRow(vendor string, model string, detectedVendor string, detectedModel string) []any {
+	return []any{"1", netip.MustParseAddr("10.0.0.1"), "OLT-CENTRO", "zte", "zte",
+		"public", "", "", "", "", "", "",
+		vendor, model, detectedVendor, detectedModel, "telnet", "", "rate",
+		"", false}
+}
+
+func TestQueryOltHostsModel(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []any
+		want string
+	}{
+		{"detected on the configured vendor", fakeHostRow("zte", "c300", "zte", "c320"), "c320"},
+		{"detected on another vendor", fakeHostRow("zte", "c300", "vsol", "v1600g"), "c300"},
+		{"detected on another vendor without model", fakeHostRow("zte", "", "vsol", "v1600g"), ""},
+		{"not detected", fakeHostRow("cdata", "fd1104", "", ""), "fd1104"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosts, err := queryOltHosts(context.Background(), fakeHostQuerier{tt.row}, "")
+			if err != nil {
+				t.Fatalf("queryOltHosts() error = %v", err)
+			}
+			if len(hosts) != 1 || hosts[0].Model != tt.want {
+				t.Errorf("queryOltHosts() = %+v, want model %q", hosts, tt.want)
+			}
+		})
+	}
+}
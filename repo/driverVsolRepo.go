@@ -0,0 +1,192 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ired.com/olt/models"
+	"ired.com/olt/utils"
+)
+
+type vsolDriver struct{}
+
+func init() {
+	registerDriver("vsol", vsolDriver{})
+}
+
+func (vsolDriver) Clock(host models.HostInfo) (string, time.Time, error) {
+	// Connect to the OLT
+	conn, err := cliConnect("vsol", host)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//send command and read response
+	response, err := cliRun(conn, host, "clock", nil, 2*time.Second)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	conn.Close()
+
+	//clean response and parse it
+	response = cleanZteDate(response)
+	for _, line := range strings.Split(response, "\n") {
+		if strings.Contains(line, "current date") {
+			response = strings.TrimSpace(strings.ReplaceAll(strings.ToLower(line), "the current date/time is :", ""))
+		}
+	}
+
+	// Parse the string into a time.Time object
+	loc, err := time.LoadLocation("America/Caracas")
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error loading location: %w", err)
+	}
+
+	// Define the layout for parsing
+	layout := "Mon Jan 02 15:04:05 2006"
+	t, err := time.ParseInLocation(layout, response, loc)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error parsing date: %w", err)
+	}
+
+	return response, t, nil
+}
+
+func (vsolDriver) ChassisInfo(host models.HostInfo) ([]oltMetric, error) {
+	var result []oltMetric
+
+	// Connect to the OLT via telnet to get temp, cpu and fan
+	connTelnet, err := cliConnect("vsol", host)
+	if err != nil {
+		return nil, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer connTelnet.Close()
+
+	//send command and read response - get temperature
+	response, err := cliRun(connTelnet, host, "fan", nil, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	response = utils.OltCleanOutput(response)
+	for _, line := range strings.Split(response, "\n") {
+		if strings.Contains(line, "current temperature") {
+			response = strings.ReplaceAll(line, "current temperature:", "")
+			response = strings.TrimSpace(strings.ReplaceAll(response, ".c", ""))
+
+			if response != "" {
+				//use oid of zte olt
+				result = append(result, oltMetric{Sn: ".1.3.6.1.4.1.3902.1082.10.10.2.1.5.1.3.1.1", Name: "olt-temperature", Value: response, Table: "detalle_int"})
+			}
+		}
+	}
+
+	connTelnet.Close()
+
+	//connect to snmp
+	connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.Snmp, 4, 4, true)
+	if err != nil {
+		return nil, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer connSnmp.Conn.Close()
+
+	//get modelo and uptime via snmp
+	oids := []string{
+		".1.3.6.1.2.1.1.1.0",                 //modelo
+		".1.3.6.1.4.1.37950.1.1.5.10.12.3.0", //cpu
+		".1.3.6.1.2.1.1.3.0",                 //uptime
+	}
+	resultSnmp, err := connSnmp.Get(oids)
+	if err != nil {
+		return nil, fmt.Errorf("error performing Get for OIDs %v: %w", oids, err)
+	}
+	for _, value := range resultSnmp.Variables {
+		oid := value.Name
+
+		//change the oid to use in DB the same as the OLT ZTEs
+		switch oid {
+		case ".1.3.6.1.2.1.1.1.0":
+			modelo := "vsol " + strings.ToLower(fmt.Sprintf("%s", value.Value))
+			result = append(result, oltMetric{Sn: oid, Name: "olt-devmodel", Value: modelo, Table: "detalle_text"})
+		case ".1.3.6.1.4.1.37950.1.1.5.10.12.3.0":
+			cpu := fmt.Sprintf("%d", value.Value)
+			result = append(result, oltMetric{Sn: ".1.9.1.1.1", Name: "olt-card-cpuload-1", Value: cpu, Table: "detalle_int"})
+		case ".1.3.6.1.2.1.1.3.0":
+			uptime := fmt.Sprintf("%d", value.Value)
+			result = append(result, oltMetric{Sn: oid, Name: "olt-uptime", Value: uptime, Table: "detalle_int"})
+		}
+	}
+
+	connSnmp.Conn.Close()
+
+	return result, nil
+}
+
+// the onu tables of vsol are described on catalog/oids.json, indexed by ponId.onuId
+
+// vsol only exposes the counters of the onus, the rates are calculated between runs
+func (vsolDriver) OnuTable(host models.HostInfo, names []string) (metricTable, error) {
+	return fetchMetricTable(host, "vsol", names)
+}
+
+func (vsolDriver) OnuTraffic(host models.HostInfo) ([]itemsTrafficOnu, error) {
+	return onuTrafficFromCounters(host, "vsol")
+}
+
+func (vsolDriver) SaveConfig(host models.HostInfo) error {
+	// Connect to the OLT, the session is left on config mode
+	conn, err := cliConnect("vsol", host)
+	if err != nil {
+		return fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//send command and read response
+	response, err := cliRun(conn, host, "save_config", nil, 55*time.Second)
+	if err != nil {
+		return err
+	}
+	conn.Close()
+
+	//the olt prints the error on the same session, the write is only confirmed if there is none
+	if err := saveConfigFailed(response); err != nil {
+		return fmt.Errorf("error proccesing 'write': %w", err)
+	}
+
+	return nil
+}
+
+func (vsolDriver) UncfgOnus(host models.HostInfo) ([]uncfgOnu, error) {
+	// Connect to the OLT
+	conn, err := cliConnect("vsol", host)
+	if err != nil {
+		return nil, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//send command and read response
+	response, err := cliRun(conn, host, "uncfg_onus", nil, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	conn.Close()
+
+	return parseUncfgOnus(response), nil
+}
+
+func (vsolDriver) ProvisionOnu(host models.HostInfo, onu models.OnuProvision) (int, error) {
+	return 0, errDriverUnsupported
+}
+
+func (vsolDriver) DeprovisionOnu(host models.HostInfo, sn string) (string, int, error) {
+	return "", 0, errDriverUnsupported
+}
+
+func (vsolDriver) OnuAction(host models.HostInfo, sn string, action string) (string, error) {
+	return "", errDriverUnsupported
+}
+
+func (vsolDriver) OnuBandwidth(host models.HostInfo, sn string, upProfile string, downProfile string) error {
+	return errDriverUnsupported
+}
@@ -0,0 +1,416 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"ired.com/olt/catalog"
+	"ired.com/olt/clitemplate"
+	"ired.com/olt/models"
+	"ired.com/olt/utils"
+)
+
+type zteDriver struct{}
+
+func init() {
+	registerDriver("zte", zteDriver{})
+}
+
+func (zteDriver) Clock(host models.HostInfo) (string, time.Time, error) {
+	// Connect to the OLT
+	conn, err := cliConnect("zte", host)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//send command and read response
+	response, err := cliRun(conn, host, "clock", nil, 2*time.Second)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	conn.Close()
+
+	//clean response and store it
+	dateOlt := cleanZteDate(response)
+
+	// Parse the string into a time.Time object
+	loc, err := time.LoadLocation("America/Caracas")
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error loading location: %w", err)
+	}
+
+	// Define the layout for parsing
+	layout := "15:04:05 Mon Jan 02 2006"
+	t, err := time.ParseInLocation(layout, dateOlt[:24], loc)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error parsing date: %w", err)
+	}
+
+	return dateOlt, t, nil
+}
+
+func (zteDriver) ChassisInfo(host models.HostInfo) ([]oltMetric, error) {
+	var result []oltMetric
+
+	//create context
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	//connect to snmp
+	connSnmp, err := utils.OltSnmpConnect(host.Ip.String(), host.Snmp, 4, 4, true)
+	if err != nil {
+		return nil, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer connSnmp.Conn.Close()
+
+	//get cards info and fan speed, olt-card-status is boardStatus:
+	// 1 inService, 2 notInService, 3 hwOnline, 4 hwOffline, 5 configuring, 6 configFailed,
+	// 7 MIB value Mismatch, 8 deactived, 9 faulty, 10 invalid, 11 noPower
+	for _, name := range []string{"olt-card-type", "olt-card-status", "olt-card-cpuload", "olt-fan"} {
+		metric, err := catalog.Lookup("zte", host.Model, name)
+		if err != nil {
+			return nil, err
+		}
+
+		walker := utils.SnmpWalk(ctx, connSnmp, metric.Oid, 0, 2)
+		for value := range walker.Rows {
+			if row, ok := metric.ParseRow(value); ok {
+				result = append(result, oltMetric{Sn: metric.ItemSn(value.Name, row.Index), Name: metric.ItemName(row.Index), Value: row.Value, Table: metric.Table})
+			}
+		}
+		if err := walker.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	//get olt general info
+	oids := []string{
+		".1.3.6.1.2.1.1.1.0",                         //modelo
+		".1.3.6.1.4.1.3902.1082.10.10.2.1.5.1.3.1.1", //temperature
+		".1.3.6.1.2.1.1.3.0",                         //uptime
+	}
+	resultSnmp, err := connSnmp.Get(oids)
+	if err != nil {
+		return nil, fmt.Errorf("error performing Get for OIDs %v: %w", oids, err)
+	}
+	for _, value := range resultSnmp.Variables {
+		oid := value.Name
+
+		switch oid {
+		case ".1.3.6.1.2.1.1.1.0":
+			modelo := strings.ToLower(fmt.Sprintf("%s", value.Value))
+			modelo = strings.ReplaceAll(modelo, ", copyright (c) by zte corporation compiled", "")
+			modelo = strings.TrimSpace(strings.ReplaceAll(modelo, "software", ""))
+			//the model c300 mini is reported as c300 on sysDescr, olt_discovery finds it by the cards
+			if host.Model == "c300 mini" {
+				modelo = strings.ReplaceAll(modelo, "c300", "c300 mini")
+			}
+			result = append(result, oltMetric{Sn: oid, Name: "olt-devmodel", Value: modelo, Table: "detalle_text"})
+		case ".1.3.6.1.4.1.3902.1082.10.10.2.1.5.1.3.1.1":
+			temperatura := fmt.Sprintf("%d", value.Value)
+			result = append(result, oltMetric{Sn: oid, Name: "olt-temperature", Value: temperatura, Table: "detalle_int"})
+		case ".1.3.6.1.2.1.1.3.0":
+			uptime := fmt.Sprintf("%d", value.Value)
+			result = append(result, oltMetric{Sn: oid, Name: "olt-uptime", Value: uptime, Table: "detalle_int"})
+		}
+	}
+
+	connSnmp.Conn.Close()
+
+	return result, nil
+}
+
+func (zteDriver) OnuTable(host models.HostInfo, names []string) (metricTable, error) {
+	return fetchMetricTable(host, "zte", names)
+}
+
+func (zteDriver) OnuTraffic(host models.HostInfo) ([]itemsTrafficOnu, error) {
+	//the rates of the olt are averaged over a window we dont know, with onu_traffic_mode counters the rates are calculated here
+	if host.TrafficMode == "counters" {
+		return onuTrafficFromCounters(host, "zte")
+	}
+
+	//sn and rates of every onu on the same pass
+	table, err := fetchMetricTable(host, "zte", []string{"onu-traffic-sn", "onu-rx-octet-rate", "onu-tx-octet-rate", "onu-rx-pkt-rate", "onu-tx-pkt-rate"})
+	if err != nil {
+		return nil, err
+	}
+
+	var items []itemsTrafficOnu
+	for snmpIndex, row := range table {
+		onuSn, ok := row["onu-traffic-sn"]
+		if !ok {
+			continue
+		}
+		items = append(items, itemsTrafficOnu{
+			snmpIndex:   snmpIndex,
+			onuSn:       onuSn,
+			RxOctetRate: utils.BytesToKb(row["onu-rx-octet-rate"]),
+			TxOctetRate: utils.BytesToKb(row["onu-tx-octet-rate"]),
+			RxPktRate:   utils.StringToInt64(row["onu-rx-pkt-rate"]),
+			TxPktRate:   utils.StringToInt64(row["onu-tx-pkt-rate"]),
+		})
+	}
+
+	return items, nil
+}
+
+func (zteDriver) SaveConfig(host models.HostInfo) error {
+	// Connect to the OLT
+	conn, err := cliConnect("zte", host)
+	if err != nil {
+		return fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//send command and read response
+	if _, err = cliRun(conn, host, "save_config", nil, 55*time.Second); err != nil {
+		return err
+	}
+	conn.Close()
+
+	return nil
+}
+
+func (zteDriver) UncfgOnus(host models.HostInfo) ([]uncfgOnu, error) {
+	// Connect to the OLT
+	conn, err := cliConnect("zte", host)
+	if err != nil {
+		return nil, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//send command and read response
+	response, err := cliRun(conn, host, "uncfg_onus", nil, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	conn.Close()
+
+	return parseUncfgOnus(response), nil
+}
+
+// id of the onus registered on the OnuIndex column of 'show gpon onu state', 1/2/1:7 (some versions print gpon-onu_1/2/1:7)
+var reZteOnuId = regexp.MustCompile(`(?m)^\s*(?:gpon-onu_)?\d+/\d+/\d+:(\d+)\s`)
+
+// interface of the onu on the output of 'show gpon onu by sn'
+var reZteOnuIf = regexp.MustCompile(`gpon-onu_(\d+/\d+/\d+):(\d+)`)
+
+// the zte olts accept up to 128 onus by pon port
+const zteMaxOnuId = 128
+
+// zteFindOnu returns the interface, pon port and id of the onu with the gpon sn, the interface is empty if the onu is not registered
+func zteFindOnu(conn *utils.CliSession, host models.HostInfo, sn string) (string, string, int, error) {
+	response, err := cliRun(conn, host, "onu_find", map[string]any{"sn": sn}, 10*time.Second)
+	if err != nil {
+		return "", "", 0, err
+	}
+	onuIf, port, onuId := zteParseOnuIf(response)
+	return onuIf, port, onuId, nil
+}
+
+// zteParseOnuIf returns the interface, pon port and id of the onu on the output of 'show gpon onu by sn', empty if it is not there
+func zteParseOnuIf(response string) (string, string, int) {
+	match := reZteOnuIf.FindStringSubmatch(response)
+	if len(match) < 3 {
+		return "", "", 0
+	}
+	return match[0], match[1], int(utils.StringToInt64(match[2]))
+}
+
+// zteFreeOnuId returns the lowest id not used on the output of 'show gpon onu state', 0 if the pon port is full
+func zteFreeOnuId(response string) int {
+	used := map[int]bool{}
+	for _, match := range reZteOnuId.FindAllStringSubmatch(response, -1) {
+		used[int(utils.StringToInt64(match[1]))] = true
+	}
+	for id := 1; id <= zteMaxOnuId; id++ {
+		if !used[id] {
+			return id
+		}
+	}
+	return 0
+}
+
+func (zteDriver) ProvisionOnu(host models.HostInfo, onu models.OnuProvision) (int, error) {
+	// Connect to the OLT
+	conn, err := cliConnect("zte", host)
+	if err != nil {
+		return 0, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//the sn can only be registered once on the olt
+	onuIf, _, _, err := zteFindOnu(conn, host, onu.Sn)
+	if err != nil {
+		return 0, err
+	}
+	if onuIf != "" {
+		return 0, fmt.Errorf("the onu %s is already registered on the olt as %s", onu.Sn, onuIf)
+	}
+
+	//find the first free id of the pon port
+	response, err := cliRun(conn, host, "onu_state", map[string]any{"port": onu.Port}, 10*time.Second)
+	if err != nil {
+		return 0, err
+	}
+	onuId := zteFreeOnuId(response)
+	if onuId == 0 {
+		return 0, fmt.Errorf("there is no free onu id on gpon-olt_%s", onu.Port)
+	}
+
+	data := map[string]any{
+		"port":     onu.Port,
+		"onu_id":   onuId,
+		"onu_type": onu.OnuType,
+		"sn":       onu.Sn,
+		"name":     onuProvisionName(onu),
+		"oldid":    onu.OldId,
+		"profile":  onu.Profile,
+		"vlan":     onu.Vlan,
+	}
+
+	//register the onu on the pon port
+	if _, err := cliRun(conn, host, "onu_register", data, 10*time.Second); err != nil {
+		conn.Send("end", 5*time.Second)
+		return 0, err
+	}
+
+	//name, bandwidth and service of the onu, the vlan is the same on the olt and on the onu
+	if _, err := cliRun(conn, host, "onu_service", data, 10*time.Second); err != nil {
+		//remove the onu so it is not left half configured on the olt
+		conn.Send("end", 5*time.Second)
+		if _, errRemove := cliRun(conn, host, "onu_remove", data, 10*time.Second); errRemove != nil {
+			utils.Logline("error removing onu half configured", host.Ip.String(), host.Name, onu.Sn, errRemove)
+			conn.Send("end", 5*time.Second)
+		}
+		return 0, fmt.Errorf("error configuring gpon-onu_%s:%d: %w", onu.Port, onuId, err)
+	}
+	conn.Close()
+
+	return onuId, nil
+}
+
+func (zteDriver) DeprovisionOnu(host models.HostInfo, sn string) (string, int, error) {
+	// Connect to the OLT
+	conn, err := cliConnect("zte", host)
+	if err != nil {
+		return "", 0, fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//find the pon port and id of the onu
+	onuIf, port, onuId, err := zteFindOnu(conn, host, sn)
+	if err != nil {
+		return "", 0, err
+	}
+	if onuIf == "" {
+		return "", 0, fmt.Errorf("the onu %s is not registered on the olt", sn)
+	}
+
+	if _, err := cliRun(conn, host, "onu_remove", map[string]any{"port": port, "onu_id": onuId}, 10*time.Second); err != nil {
+		conn.Send("end", 5*time.Second)
+		return "", 0, err
+	}
+	conn.Close()
+
+	return port, onuId, nil
+}
+
+// the action is the template onu_<action>, the ones of reboot and reset ask for confirmation
+func (zteDriver) OnuAction(host models.HostInfo, sn string, action string) (string, error) {
+	// Connect to the OLT
+	conn, err := cliConnect("zte", host)
+	if err != nil {
+		return "", fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//find the interface of the onu
+	onuIf, _, _, err := zteFindOnu(conn, host, sn)
+	if err != nil {
+		return "", err
+	}
+	if onuIf == "" {
+		return "", fmt.Errorf("the onu %s is not registered on the olt", sn)
+	}
+
+	response, err := cliRun(conn, host, "onu_"+action, map[string]any{"interface": onuIf}, 15*time.Second)
+	if err != nil {
+		conn.Send("end", 5*time.Second)
+		return utils.OltCleanOutput(response), fmt.Errorf("%w on %s", err, onuIf)
+	}
+	conn.Close()
+
+	return utils.OltCleanOutput(response), nil
+}
+
+// the upstream is the dba profile of the tcont 1 and the downstream the traffic limit of the gemport 1, as configured by ProvisionOnu
+func (zteDriver) OnuBandwidth(host models.HostInfo, sn string, upProfile string, downProfile string) error {
+	// Connect to the OLT
+	conn, err := cliConnect("zte", host)
+	if err != nil {
+		return fmt.Errorf("couldnt establish connection: %w", err)
+	}
+	defer conn.Close()
+
+	//find the interface of the onu
+	onuIf, _, _, err := zteFindOnu(conn, host, sn)
+	if err != nil {
+		return err
+	}
+	if onuIf == "" {
+		return fmt.Errorf("the onu %s is not registered on the olt", sn)
+	}
+
+	data := map[string]any{"interface": onuIf, "up_profile": upProfile, "down_profile": downProfile}
+	if _, err := cliRun(conn, host, "onu_bandwidth", data, 10*time.Second); err != nil {
+		conn.Send("end", 5*time.Second)
+		return fmt.Errorf("%w on %s", err, onuIf)
+	}
+
+	//read the config back, the olt can accept a command and keep the previous profile
+	response, err := cliRun(conn, host, "onu_config", data, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	conn.Close()
+
+	expected, err := clitemplate.Render(host.Vendor, host.Model, "onu_bandwidth_verify", data)
+	if err != nil {
+		return err
+	}
+	if line, ok := zteConfigHasLines(response, expected); !ok {
+		return fmt.Errorf("'%s' not satisfied by the config of %s", line, onuIf)
+	}
+
+	return nil
+}
+
+// zteConfigHasLines reports if every expected line is a whole line of the running config, the spaces and case are ignored
+// so a profile is never matched by a prefix of another one. an expected line "no <command>" means that no line of the config
+// starts with the command, as left by the no form of the cli. returns the first line not satisfied
+func zteConfigHasLines(response string, expected []string) (string, bool) {
+	var config []string
+	for _, line := range strings.Split(response, "\n") {
+		config = append(config, strings.ToLower(strings.Join(strings.Fields(line), " ")))
+	}
+	for _, line := range expected {
+		want := strings.ToLower(strings.Join(strings.Fields(line), " "))
+		if removed, ok := strings.CutPrefix(want, "no "); ok {
+			if slices.ContainsFunc(config, func(have string) bool { return have == removed || strings.HasPrefix(have, removed+" ") }) {
+				return line, false
+			}
+			continue
+		}
+		if !slices.Contains(config, want) {
+			return line, false
+		}
+	}
+	return "", true
+}
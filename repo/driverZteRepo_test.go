@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// output of 'show gpon onu state gpon-olt_1/2/1' on a C320 v2.1
+const zteOnuStateC320 = `OnuIndex   Admin State  OMCC State  Phase State  Channel
+--------------------------------------------------------------
+1/2/1:1     enable       enable      working      1(GPON)
+1/2/1:2     enable       enable      working      1(GPON)
+1/2/1:3     enable       disable     offline      1(GPON)
+1/2/1:5     enable       enable      working      1(GPON)
+1/2/1:12    enable       enable      los          1(GPON)
+ONU Number: 5/5`
+
+// same output on older versions of C300, with the prefix of the interface
+const zteOnuStateC300Prefix = `OnuIndex                 Admin State  OMCC State  Phase State  Channel
+-----------------------------------------------------------------------------
+gpon-onu_1/3/4:1         enable       enable      working      1(GPON)
+gpon-onu_1/3/4:2         enable       enable      working      1(GPON)
+ONU Number: 2/2`
+
+func TestZteFreeOnuId(t *testing.T) {
+	var full []string
+	for id := 1; id <= zteMaxOnuId; id++ {
+		full = append(full, fmt.Sprintf("1/1/1:%d     enable       enable      working      1(GPON)", id))
+	}
+
+	tests := []struct {
+		name     string
+		response string
+		want     int
+	}{
+		{"c320 with gap", zteOnuStateC320, 4},
+		{"c300 with prefix", zteOnuStateC300Prefix, 3},
+		{"empty port", "OnuIndex   Admin State  OMCC State  Phase State  Channel\n----\nONU Number: 0/0", 1},
+		{"full port", strings.Join(full, "\n"), 0},
+		{"ignores the onu number line", "ONU Number: 1/1\n1/2/1:1     enable       enable      working      1(GPON)", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := zteFreeOnuId(tt.response); got != tt.want {
+				t.Errorf("zteFreeOnuId() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// output of 'show running-config interface gpon-onu_1/2/1:5' on a C320
+const zteOnuConfig = "show running-config interface gpon-onu_1/2/1:5\r\n" +
+	"Building configuration...\r\n" +
+	"interface gpon-onu_1/2/1:5\r\n" +
+	"  name CLIENTE_-_1234\r\n" +
+	"  tcont 1 profile UP-50M2\r\n" +
+	"  gemport 1 name Internet tcont 1\r\n" +
+	"  gemport 1 traffic-limit  downstream DW-100M\r\n" +
+	"!\r\n" +
+	"end\r\n" +
+	"ZXAN#"
+
+// same config after 'no gemport 1 traffic-limit downstream'
+var zteOnuConfigUnlimited = strings.Replace(zteOnuConfig, "  gemport 1 traffic-limit  downstream DW-100M\r\n", "", 1)
+
+func TestZteConfigHasLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected []string
+		wantLine string
+		wantOk   bool
+	}{
+		{"up and down", zteOnuConfig, []string{"tcont 1 profile UP-50M2", "gemport 1 traffic-limit downstream DW-100M"}, "", true},
+		{"case and spaces", zteOnuConfig, []string{"TCONT 1  PROFILE up-50m2"}, "", true},
+		{"prefix of another profile", zteOnuConfig, []string{"tcont 1 profile UP-50M"}, "tcont 1 profile UP-50M", false},
+		{"down not applied", zteOnuConfig, []string{"tcont 1 profile UP-50M2", "gemport 1 traffic-limit downstream DW-200M"}, "gemport 1 traffic-limit downstream DW-200M", false},
+		{"part of a line", zteOnuConfig, []string{"traffic-limit downstream DW-100M"}, "traffic-limit downstream DW-100M", false},
+		{"down not removed", zteOnuConfig, []string{"tcont 1 profile UP-50M2", "no gemport 1 traffic-limit downstream"}, "no gemport 1 traffic-limit downstream", false},
+		{"down removed", zteOnuConfigUnlimited, []string{"tcont 1 profile UP-50M2", "no gemport 1 traffic-limit downstream"}, "", true},
+		{"removed keeps the other gemport lines", zteOnuConfigUnlimited, []string{"no gemport 1 traffic-limit"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, ok := zteConfigHasLines(tt.config, tt.expected)
+			if line != tt.wantLine || ok != tt.wantOk {
+				t.Errorf("zteConfigHasLines() = %q, %v, want %q, %v", line, ok, tt.wantLine, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestZteParseOnuIf(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  string
+		wantIf    string
+		wantPort  string
+		wantOnuId int
+	}{
+		{"registered", "SearchResult\r\n-----------------\r\ngpon-onu_1/2/1:5\r\n", "gpon-onu_1/2/1:5", "1/2/1", 5},
+		{"two digits", "SearchResult\r\n-----------------\r\ngpon-onu_1/12/16:128\r\n", "gpon-onu_1/12/16:128", "1/12/16", 128},
+		{"not registered", "%Code 32310-GPONSRV : No related information to show.\r\n", "", "", 0},
+		{"echo of the command only", "show gpon onu by sn ZTEGC8A1B2C3\r\n", "", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onuIf, port, onuId := zteParseOnuIf(tt.response)
+			if onuIf != tt.wantIf || port != tt.wantPort || onuId != tt.wantOnuId {
+				t.Errorf("zteParseOnuIf() = %q, %q, %d, want %q, %q, %d", onuIf, port, onuId, tt.wantIf, tt.wantPort, tt.wantOnuId)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	g "github.com/gosnmp/gosnmp"
+	"ired.com/olt/catalog"
+	"ired.com/olt/models"
+	"ired.com/olt/utils"
+)
+
+// traps that only notify the event of the onu, the index is taken from the varbind of the metric of the catalog
+type trapOnuEvent struct {
+	status string // key of catalog.OnuStatusCodes
+	metric string
+}
+
+var trapOnuEvents = map[string]trapOnuEvent{
+	".1.3.6.1.4.1.3902.1082.500.20.2.2.2.0.2": {status: "los", metric: "onu-name"},            // zte onu los
+	".1.3.6.1.4.1.3902.1082.500.20.2.2.2.0.3": {status: "dyinggasp", metric: "onu-name"},      // zte onu dying gasp
+	".1.3.6.1.4.1.37950.1.1.6.2.0.2":          {status: "los", metric: "onu-name"},            // vsol onu los
+	".1.3.6.1.4.1.37950.1.1.6.2.0.3":          {status: "dyinggasp", metric: "onu-name"},      // vsol onu dying gasp
+	".1.3.6.1.4.1.17409.2.8.0.12":             {status: "los", metric: "onu-name"},            // cdata gpon onu los
+	".1.3.6.1.4.1.17409.2.8.0.13":             {status: "dyinggasp", metric: "onu-name"},      // cdata gpon onu dying gasp
+	".1.3.6.1.4.1.17409.2.3.0.12":             {status: "los", metric: "onu-name-epon"},       // cdata epon onu los
+	".1.3.6.1.4.1.17409.2.3.0.13":             {status: "dyinggasp", metric: "onu-name-epon"}, // cdata epon onu dying gasp
+}
+
+// generic traps of the olt, stored as text on the item olt-trap
+var trapOltEvents = map[string]string{
+	".1.3.6.1.6.3.1.1.5.1": "coldStart",
+	".1.3.6.1.6.3.1.1.5.2": "warmStart",
+	".1.3.6.1.6.3.1.1.5.3": "linkDown",
+	".1.3.6.1.6.3.1.1.5.4": "linkUp",
+}
+
+// TrapHosts returns the active olts keyed by ip, the traps of any other source are dropped
+func TrapHosts(db models.ConnDb) (map[string]models.HostInfo, error) {
+	hostsInfo, err := getOltHosts(db.Ctx, db.Conn)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make(map[string]models.HostInfo, len(hostsInfo))
+	for _, host := range hostsInfo {
+		hosts[host.Ip.String()] = host
+	}
+	return hosts, nil
+}
+
+// TrapAuthorized reports if the trap was sent with the credentials of the olt: the trap community on v1 and v2c,
+// and on v3 the user of the olt with the security level of its passwords, the usm of the listener already checked them
+func TrapAuthorized(host models.HostInfo, packet *g.SnmpPacket) bool {
+	if packet.Version != g.Version3 {
+		return host.Snmp.TrapCommunity != "" && packet.Community == host.Snmp.TrapCommunity
+	}
+
+	if host.Snmp.User == "" || packet.SecurityModel != g.UserSecurityModel {
+		return false
+	}
+	security, ok := packet.SecurityParameters.(*g.UsmSecurityParameters)
+	if !ok || security.UserName != host.Snmp.User {
+		return false
+	}
+	_, msgFlags, err := utils.SnmpV3Security(host.Snmp)
+	if err != nil {
+		return false
+	}
+	return packet.MsgFlags&g.AuthPriv == msgFlags
+}
+
+// OltTrap records on the statistics tables the events of the trap or inform received from the olt
+func OltTrap(db models.ConnDb, packet *g.SnmpPacket, host models.HostInfo) error {
+	trapOid := trapOidFromPacket(packet)
+
+	//olt events
+	if event, ok := trapOltEvents[trapOid]; ok {
+		itemId := createItemSnmp(db, host.Id, host.Ip, "olt-trap", "olt-trap")
+		if itemId == "" {
+			return fmt.Errorf("couldnt create item olt-trap")
+		}
+		utils.Logline("olt trap "+event, host.Ip.String(), host.Name)
+		return insertEstadistica(db, []models.ItemResult{{ItemId: itemId, Value: event, Table: "detalle_text"}}, host.Ip.String(), "oltTrap")
+	}
+
+	//onu events, the status comes on the varbinds of the onu-status columns or is defined by the trap
+	var values []onuValue
+	for _, metric := range catalog.Metrics(host.Vendor) {
+		if metric.Item != "onu-status" {
+			continue
+		}
+		for _, value := range packet.Variables {
+			if row, ok := metric.ParseRow(value); ok {
+				values = append(values, onuValue{snmpIndex: row.Index, value: row.Value})
+			}
+		}
+	}
+	if event, ok := trapOnuEvents[trapOid]; ok && len(values) == 0 {
+		if metric, err := catalog.Lookup(host.Vendor, host.Model, event.metric); err == nil {
+			for _, value := range packet.Variables {
+				if index, ok := metric.ParseIndex(value.Name); ok {
+					values = append(values, onuValue{snmpIndex: index, value: catalog.OnuStatusCodes[event.status]})
+					break
+				}
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		utils.Logline("trap without onu or olt events "+trapOid, host.Ip.String(), host.Name)
+		return nil
+	}
+
+	var results []models.ItemResult
+	for _, value := range values {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		var itemId string
+		query := `SELECT id FROM network.host_item WHERE host_id=$1 AND nombre='onu-status' AND sn=$2 AND activo=true LIMIT 1`
+		err := db.Conn.QueryRow(ctx, query, host.Id, value.snmpIndex).Scan(&itemId)
+		cancel()
+		if err != nil {
+			utils.Logline("trap of onu without item onu-status "+value.snmpIndex, host.Ip.String(), host.Name, err)
+			continue
+		}
+
+		utils.Logline(fmt.Sprintf("onu trap %s status (%s)", value.snmpIndex, value.value), host.Ip.String(), host.Name)
+		results = append(results, models.ItemResult{ItemId: itemId, Value: value.value, Table: "detalle_int"})
+	}
+
+	if len(results) == 0 {
+		return nil
+	}
+	return insertEstadistica(db, results, host.Ip.String(), "onuTrap")
+}
+
+// trapOidFromPacket returns snmpTrapOID.0 of v2c and v3, on v1 it is built from the enterprise and trap numbers like RFC 3584
+func trapOidFromPacket(packet *g.SnmpPacket) string {
+	if packet.Version == g.Version1 {
+		if packet.GenericTrap < 6 {
+			return fmt.Sprintf(".1.3.6.1.6.3.1.1.5.%d", packet.GenericTrap+1)
+		}
+		return fmt.Sprintf("%s.0.%d", packet.Enterprise, packet.SpecificTrap)
+	}
+
+	for _, value := range packet.Variables {
+		if value.Name == ".1.3.6.1.6.3.1.1.4.1.0" { //snmpTrapOID.0
+			return fmt.Sprintf("%s", value.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"testing"
+
+	g "github.com/gosnmp/gosnmp"
+	"ired.com/olt/models"
+)
+
+func TestTrapAuthorized(t *testing.T) {
+	v2c := models.HostInfo{Snmp: models.SnmpAuth{Community: "public", TrapCommunity: "traps"}}
+	v3 := models.HostInfo{Snmp: models.SnmpAuth{User: "monitor", AuthProtocol: "sha", AuthPassword: "secret1234", PrivProtocol: "aes", PrivPassword: "secret1234"}}
+	v3Packet := func(user string, msgFlags g.SnmpV3MsgFlags) *g.SnmpPacket {
+		return &g.SnmpPacket{Version: g.Version3, SecurityModel: g.UserSecurityModel, MsgFlags: msgFlags, SecurityParameters: &g.UsmSecurityParameters{UserName: user}}
+	}
+
+	tests := []struct {
+		name   string
+		host   models.HostInfo
+		packet *g.SnmpPacket
+		want   bool
+	}{
+		{"v2c trap community", v2c, &g.SnmpPacket{Version: g.Version2c, Community: "traps"}, true},
+		{"v1 trap community", v2c, &g.SnmpPacket{Version: g.Version1, Community: "traps"}, true},
+		{"v2c read community", v2c, &g.SnmpPacket{Version: g.Version2c, Community: "public"}, false},
+		{"v2c without community", models.HostInfo{}, &g.SnmpPacket{Version: g.Version2c}, false},
+		{"v3 on v2c olt", v2c, v3Packet("monitor", g.AuthPriv), false},
+		{"v3 authPriv", v3, v3Packet("monitor", g.AuthPriv|g.Reportable), true},
+		{"v3 other user", v3, v3Packet("other", g.AuthPriv), false},
+		{"v3 lower level", v3, v3Packet("monitor", g.AuthNoPriv), false},
+		{"v3 noAuthNoPriv", v3, v3Packet("monitor", g.NoAuthNoPriv), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TrapAuthorized(tt.host, tt.packet); got != tt.want {
+				t.Errorf("TrapAuthorized() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
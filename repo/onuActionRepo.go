@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"ired.com/olt/models"
+	"ired.com/olt/utils"
+)
+
+// gpon sn stored on the item onu-sn, vendor id and 8 hex digits with or without separator
+var reOnuGponSn = regexp.MustCompile(`(?i)([a-z]{4})[,:\-\s]?([0-9a-f]{8})`)
+
+// RunOnuAction finds the olt of the onu by oldid or sn on network.host_item, runs the action on the onu and records it on network.onu_action
+func RunOnuAction(db models.ConnDb, request models.OnuActionRequest) (models.OnuActionResult, error) {
+	request.Sn = strings.ToUpper(strings.TrimSpace(request.Sn))
+	if request.OldId == "" && request.Sn == "" {
+		return models.OnuActionResult{}, fmt.Errorf("oldid or sn is required")
+	}
+	if request.Sn != "" && !reOnuProvisionSn.MatchString(request.Sn) {
+		return models.OnuActionResult{}, fmt.Errorf("invalid sn %q", request.Sn)
+	}
+
+	hostId, oldId, sn, err := findOnuHost(db, request.OldId, request.Sn)
+	if err != nil {
+		return models.OnuActionResult{}, err
+	}
+	result := models.OnuActionResult{HostId: hostId, OldId: oldId, Sn: sn, Action: request.Action}
+
+	host, err := getOltHost(db.Ctx, db.Conn, hostId)
+	if err != nil {
+		return result, fmt.Errorf("error getting olt: %w", err)
+	}
+
+	driver, err := getDriver(host.Vendor)
+	if err != nil {
+		return result, err
+	}
+
+	result.Output, err = driver.OnuAction(host, sn, request.Action)
+	if errors.Is(err, errDriverUnsupported) {
+		return result, fmt.Errorf("actions on onus are not supported on %s olts", host.Vendor)
+	}
+	if errLog := saveOnuAction(db, result, err); errLog != nil {
+		utils.Logline("error saving onu action", host.Ip.String(), host.Name, sn, errLog)
+	}
+	if err != nil {
+		utils.Logline("error running onu action "+request.Action, host.Ip.String(), host.Name, sn, err)
+		return result, err
+	}
+	utils.Logline("onu action "+request.Action, host.Ip.String(), host.Name, oldId, sn)
+
+	return result, nil
+}
+
+// findOnuHost returns the olt, oldid and gpon sn of the onu, the gpon sn is the last value stored of the item onu-sn.
+// the sn is compared exactly after normalizing the stored value, the sn returned is always the stored one
+func findOnuHost(db models.ConnDb, oldId string, sn string) (string, string, string, error) {
+	if sn != "" && !reOnuProvisionSn.MatchString(sn) {
+		return "", "", "", fmt.Errorf("invalid sn %q", sn)
+	}
+	if oldId != "" && !reOldId.MatchString(oldId) {
+		return "", "", "", fmt.Errorf("invalid oldid %q", oldId)
+	}
+
+	//strpos is only a prefilter without wildcards, the match is checked below
+	query := `SELECT hi.host_id::text, COALESCE(hi.oldid::text, ''), dt.value
+		FROM network.host_item as hi
+		JOIN LATERAL (
+			SELECT value FROM estadistica.detalle_text WHERE item_id=hi.id ORDER BY created_at DESC LIMIT 1
+		) as dt ON true
+		WHERE hi.nombre='onu-sn' AND hi.activo=true AND hi.sn NOT LIKE 'removed-%'
+			AND ($1='' OR hi.oldid::text=$1) AND ($2='' OR strpos(upper(regexp_replace(dt.value, '[^A-Za-z0-9]', '', 'g')), $2)>0)
+		ORDER BY hi.id DESC`
+	rows, err := db.Conn.Query(db.Ctx, query, oldId, sn)
+	if err != nil {
+		return "", "", "", err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hostId, itemOldId, value string
+		if err := rows.Scan(&hostId, &itemOldId, &value); err != nil {
+			return "", "", "", err
+		}
+		storedSn, ok := onuGponSn(value)
+		if !ok || (sn != "" && storedSn != sn) {
+			continue
+		}
+		return hostId, itemOldId, storedSn, nil
+	}
+	if err := rows.Err(); err != nil {
+		return "", "", "", err
+	}
+
+	return "", "", "", fmt.Errorf("there is no active onu with oldid '%s' and sn '%s'", oldId, sn)
+}
+
+// onuGponSn returns the gpon sn in uppercase of the value stored on the item onu-sn, the olts store it with separators or in lowercase
+func onuGponSn(value string) (string, bool) {
+	match := reOnuGponSn.FindStringSubmatch(value)
+	if len(match) < 3 {
+		return "", false
+	}
+	return strings.ToUpper(match[1] + match[2]), true
+}
+
+// saveOnuAction records the action and its outcome on network.onu_action
+func saveOnuAction(db models.ConnDb, result models.OnuActionResult, actionErr error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	output := result.Output
+	if actionErr != nil {
+		output = actionErr.Error()
+	}
+
+	query := `INSERT INTO network.onu_action (host_id, oldid, sn, action, success, output, created_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, now())`
+	_, err := db.Conn.Exec(ctx, query, result.HostId, result.OldId, result.Sn, result.Action, actionErr == nil, output)
+	return err
+}
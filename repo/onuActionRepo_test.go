@@ -0,0 +1,27 @@
+package repo
+
+import "testing"
+
+func TestOnuGponSn(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		wantOk bool
+	}{
+		{"ZTEGC8A1B2C3", "ZTEGC8A1B2C3", true},
+		{"ztegc8a1b2c3", "ZTEGC8A1B2C3", true},
+		{"ZTEG,C8A1B2C3", "ZTEGC8A1B2C3", true},
+		{"1,ZTEGC8A1B2C3", "ZTEGC8A1B2C3", true},
+		{"HWTC-1A2B3C4D", "HWTC1A2B3C4D", true},
+		{"ZTEGC8A1", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := onuGponSn(tt.value)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("onuGponSn(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ired.com/olt/models"
+	"ired.com/olt/utils"
+)
+
+// columns of network.onu_bandwidth returned as models.OnuBandwidth
+const onuBandwidthSql = `oldid, up_profile, down_profile, updated_at::text, COALESCE(applied_at::text, ''), COALESCE(last_error, '')`
+
+// SetOnuBandwidth stores the desired profiles of the onu and applies them on the olt, if the olt fails the profiles
+// stay pending and the task apply_onu_bandwidth retries them
+func SetOnuBandwidth(db models.ConnMysqlPgsql, request models.OnuBandwidthRequest) (models.OnuBandwidth, error) {
+	if err := validOnuBandwidth(request.OldId, request.UpProfile, request.DownProfile); err != nil {
+		return models.OnuBandwidth{}, err
+	}
+
+	query := `INSERT INTO network.onu_bandwidth (oldid, up_profile, down_profile, updated_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (oldid) DO UPDATE SET up_profile=EXCLUDED.up_profile, down_profile=EXCLUDED.down_profile, updated_at=EXCLUDED.updated_at,
+			applied_at=NULL, last_error=NULL
+		RETURNING ` + onuBandwidthSql
+	var state models.OnuBandwidth
+	err := db.ConnPgsql.QueryRow(db.Ctx, query, request.OldId, request.UpProfile, request.DownProfile).Scan(
+		&state.OldId, &state.UpProfile, &state.DownProfile, &state.UpdatedAt, &state.AppliedAt, &state.LastError)
+	if err != nil {
+		utils.Logline("error saving onu bandwidth", request.OldId, err)
+		return models.OnuBandwidth{}, err
+	}
+
+	if err := applyOnuBandwidth(db, &state); err != nil {
+		return state, fmt.Errorf("profiles saved but not applied on the olt: %w", err)
+	}
+
+	return state, nil
+}
+
+// GetOnuBandwidth returns the desired profiles of the onus, filtered by oldid if it is not empty
+func GetOnuBandwidth(db models.ConnDb, oldId string) ([]models.OnuBandwidth, error) {
+	query := `SELECT ` + onuBandwidthSql + `
+		FROM network.onu_bandwidth
+		WHERE $1='' OR oldid=$1
+		ORDER BY updated_at DESC`
+	rows, err := db.Conn.Query(db.Ctx, query, oldId)
+	if err != nil {
+		utils.Logline("error getting onu bandwidth", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := []models.OnuBandwidth{}
+	for rows.Next() {
+		var state models.OnuBandwidth
+		err = rows.Scan(&state.OldId, &state.UpProfile, &state.DownProfile, &state.UpdatedAt, &state.AppliedAt, &state.LastError)
+		if err != nil {
+			utils.Logline("error scanning rows of onu bandwidth", err)
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	rows.Close()
+
+	return states, nil
+}
+
+// CronOnuBandwidth applies the profiles not applied yet, the onus whose olt failed or didnt exist when the profiles were set
+func CronOnuBandwidth(db models.ConnMysqlPgsql, caller string) error {
+	//show status of worker
+	utils.Logline(utils.ShowStatusWorkerMysql(db, "onuBandwidth", caller+"/begin"))
+
+	query := `SELECT ` + onuBandwidthSql + `
+		FROM network.onu_bandwidth
+		WHERE applied_at IS NULL
+		ORDER BY updated_at ASC`
+	rows, err := db.ConnPgsql.Query(db.Ctx, query)
+	if err != nil {
+		utils.Logline("error getting pending onu bandwidth", err)
+		return err
+	}
+	defer rows.Close()
+
+	var states []models.OnuBandwidth
+	for rows.Next() {
+		var state models.OnuBandwidth
+		err = rows.Scan(&state.OldId, &state.UpProfile, &state.DownProfile, &state.UpdatedAt, &state.AppliedAt, &state.LastError)
+		if err != nil {
+			utils.Logline("error scanning rows of onu bandwidth", err)
+			return err
+		}
+		states = append(states, state)
+	}
+	rows.Close()
+
+	//the onus are applied one by one, every one opens its own session with the olt
+	applied := 0
+	for _, state := range states {
+		if db.Ctx.Err() != nil {
+			break
+		}
+		if err := applyOnuBandwidth(db, &state); err != nil {
+			utils.Logline("error applying onu bandwidth", state.OldId, err)
+			continue
+		}
+		applied++
+	}
+	utils.Logline(fmt.Sprintf("(%d) of (%d) pending onu bandwidth applied", applied, len(states)), "apply_onu_bandwidth")
+
+	//show status of worker
+	utils.Logline(utils.ShowStatusWorkerMysql(db, "onuBandwidth", caller+"/ending"))
+
+	return nil
+}
+
+// applyOnuBandwidth applies the profiles on the olt of the onu and stores the outcome, on success the onu is left pending to sync
+// so the task olt_autowrite saves the config of the olt
+func applyOnuBandwidth(db models.ConnMysqlPgsql, state *models.OnuBandwidth) error {
+	err := func() error {
+		//the rows saved before the profiles were validated are checked again, they are sent on config mode
+		if err := validOnuBandwidth(state.OldId, state.UpProfile, state.DownProfile); err != nil {
+			return err
+		}
+
+		hostId, _, sn, err := findOnuHost(models.ConnDb{Conn: db.ConnPgsql, Ctx: db.Ctx}, state.OldId, "")
+		if err != nil {
+			return err
+		}
+
+		host, err := getOltHost(db.Ctx, db.ConnPgsql, hostId)
+		if err != nil {
+			return fmt.Errorf("error getting olt: %w", err)
+		}
+
+		driver, err := getDriver(host.Vendor)
+		if err != nil {
+			return err
+		}
+
+		if err := driver.OnuBandwidth(host, sn, state.UpProfile, state.DownProfile); err != nil {
+			if errors.Is(err, errDriverUnsupported) {
+				return fmt.Errorf("profiles of bandwidth are not supported on %s olts", host.Vendor)
+			}
+			return err
+		}
+		utils.Logline("onu bandwidth applied", host.Ip.String(), host.Name, state.OldId, sn, state.UpProfile, state.DownProfile)
+
+		if err := onuPendingSync(db, host, state.OldId); err != nil {
+			utils.Logline("error updating estaciones_onu", host.Ip.String(), host.Name, state.OldId, err)
+		}
+		return nil
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	//the outcome is stored only if the profiles didnt change meanwhile
+	lastError := ""
+	if err != nil {
+		lastError = err.Error()
+	}
+	query := `UPDATE network.onu_bandwidth
+		SET applied_at=CASE WHEN $2='' THEN now() END, last_error=NULLIF($2, '')
+		WHERE oldid=$1 AND updated_at::text=$3
+		RETURNING COALESCE(applied_at::text, ''), COALESCE(last_error, '')`
+	if errSave := db.ConnPgsql.QueryRow(ctx, query, state.OldId, lastError, state.UpdatedAt).Scan(&state.AppliedAt, &state.LastError); errSave != nil {
+		utils.Logline("error saving outcome of onu bandwidth", state.OldId, errSave)
+	}
+
+	return err
+}
+
+// validOnuBandwidth checks the values rendered on the template onu_bandwidth, the downstream profile can be empty
+func validOnuBandwidth(oldId string, upProfile string, downProfile string) error {
+	if !reOldId.MatchString(oldId) {
+		return fmt.Errorf("invalid oldid %q", oldId)
+	}
+	if !reCliToken.MatchString(upProfile) {
+		return fmt.Errorf("invalid up_profile %q", upProfile)
+	}
+	if downProfile != "" && !reCliToken.MatchString(downProfile) {
+		return fmt.Errorf("invalid down_profile %q", downProfile)
+	}
+	return nil
+}
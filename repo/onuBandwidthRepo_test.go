@@ -0,0 +1,29 @@
+package repo
+
+import "testing"
+
+func TestValidOnuBandwidth(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldId       string
+		upProfile   string
+		downProfile string
+		wantErr     bool
+	}{
+		{"valid", "4521", "UP-100M", "DOWN_100M", false},
+		{"without downstream", "4521", "UP-100M", "", false},
+		{"empty upstream", "4521", "", "DOWN_100M", true},
+		{"upstream with newline", "4521", "UP-100M\nno onu 1", "", true},
+		{"downstream with newline", "4521", "UP-100M", "DOWN\nend", true},
+		{"downstream with space", "4521", "UP-100M", "DOWN 100M", true},
+		{"oldid not numeric", "45a", "UP-100M", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validOnuBandwidth(tt.oldId, tt.upProfile, tt.downProfile)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validOnuBandwidth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
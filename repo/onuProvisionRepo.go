@@ -0,0 +1,363 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"ired.com/olt/models"
+	"ired.com/olt/utils"
+)
+
+// pon port as shelf/slot/port and gpon sn as vendor id and 8 hex digits
+var (
+	reOnuProvisionPort = regexp.MustCompile(`^\d+/\d+/\d+$`)
+	reOnuProvisionSn   = regexp.MustCompile(`^[A-Za-z]{4}[0-9A-Fa-f]{8}$`)
+)
+
+// values rendered on the cli templates, every line is one command so anything else could inject commands on config mode
+var (
+	reCliToken      = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	reCliTokenChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+	reOldId         = regexp.MustCompile(`^\d+$`)
+)
+
+// ProvisionOnu authorizes the onu on the pon port of the olt, configures its service and marks the onu on estaciones_onu
+// as pending to sync, so the task olt_autowrite saves the config of the olt
+func ProvisionOnu(db models.ConnMysqlPgsql, onu models.OnuProvision) (models.OnuProvisioned, error) {
+	if err := validOnuProvision(&onu); err != nil {
+		return models.OnuProvisioned{}, err
+	}
+
+	host, err := getOltHost(db.Ctx, db.ConnPgsql, onu.HostId)
+	if err != nil {
+		return models.OnuProvisioned{}, fmt.Errorf("error getting olt: %w", err)
+	}
+
+	result, err := provisionOnu(host, onu)
+	if err != nil {
+		return models.OnuProvisioned{}, err
+	}
+
+	if err := onuPendingSync(db, host, onu.OldId); err != nil {
+		return result, fmt.Errorf("onu provisioned but error updating estaciones_onu: %w", err)
+	}
+
+	return result, nil
+}
+
+// DeprovisionOnu removes the onu from the olt, the host items of the onu are deactivated and detached from its snmp index
+// so they keep the history of the customer and are not taken by the next onu registered on the same id
+func DeprovisionOnu(db models.ConnMysqlPgsql, onu models.OnuDeprovision) (models.OnuChange, error) {
+	onu.Sn = strings.ToUpper(strings.TrimSpace(onu.Sn))
+	if !reOnuProvisionSn.MatchString(onu.Sn) {
+		return models.OnuChange{}, fmt.Errorf("invalid sn %s", onu.Sn)
+	}
+
+	host, err := getOltHost(db.Ctx, db.ConnPgsql, onu.HostId)
+	if err != nil {
+		return models.OnuChange{}, fmt.Errorf("error getting olt: %w", err)
+	}
+
+	change, err := deprovisionOnu(db, host, onu.Sn, onu.OldId)
+	if err != nil {
+		return models.OnuChange{}, err
+	}
+
+	if err := saveOnuChange(db, change); err != nil {
+		utils.Logline("error saving onu change", host.Ip.String(), host.Name, onu.Sn, err)
+		return change, fmt.Errorf("onu removed but error updating host items: %w", err)
+	}
+
+	if err := onuPendingSync(db, host, onu.OldId); err != nil {
+		return change, fmt.Errorf("onu removed but error updating estaciones_onu: %w", err)
+	}
+
+	return change, nil
+}
+
+// MoveOnu removes the onu from the olt from_host_id and provisions it on the pon port of host_id, the host items of the onu
+// are re-pointed to the new snmp index so the history stays with the customer
+func MoveOnu(db models.ConnMysqlPgsql, move models.OnuMove) (models.OnuChange, error) {
+	onu := move.OnuProvision
+	if err := validOnuProvision(&onu); err != nil {
+		return models.OnuChange{}, err
+	}
+
+	fromHost, err := getOltHost(db.Ctx, db.ConnPgsql, move.FromHostId)
+	if err != nil {
+		return models.OnuChange{}, fmt.Errorf("error getting olt: %w", err)
+	}
+	toHost, err := getOltHost(db.Ctx, db.ConnPgsql, onu.HostId)
+	if err != nil {
+		return models.OnuChange{}, fmt.Errorf("error getting olt: %w", err)
+	}
+
+	//the onu is removed first, the sn can be registered only once on the same olt
+	change, err := deprovisionOnu(db, fromHost, onu.Sn, onu.OldId)
+	if err != nil {
+		return models.OnuChange{}, err
+	}
+
+	provisioned, err := provisionOnu(toHost, onu)
+	if err != nil {
+		return restoreOnu(db, fromHost, change, onu, fmt.Errorf("onu not provisioned on %s: %w", toHost.Name, err))
+	}
+
+	change.Action = "move"
+	change.ToHostId = toHost.Id
+	change.ToPort = provisioned.Port
+	change.ToOnuId = provisioned.OnuId
+	change.ToSnmpIndex, err = onuIndexByOldId(toHost, onu.OldId)
+	if err != nil {
+		//without the new index the items are detached, get_onu_info creates the new ones
+		utils.Logline("error getting snmp index of the moved onu", toHost.Ip.String(), toHost.Name, onu.Sn, err)
+	}
+
+	if err := saveOnuChange(db, change); err != nil {
+		utils.Logline("error saving onu change", toHost.Ip.String(), toHost.Name, onu.Sn, err)
+		return change, fmt.Errorf("onu moved but error updating host items: %w", err)
+	}
+
+	//the row of the onu points now to the new olt, the old one is saved here as olt_autowrite wont find it pending
+	if err := saveOltConfig(fromHost); err != nil {
+		utils.Logline("error saving config of the olt where the onu was removed", fromHost.Ip.String(), fromHost.Name, onu.Sn, err)
+	}
+
+	if err := onuPendingSync(db, toHost, onu.OldId); err != nil {
+		return change, fmt.Errorf("onu moved but error updating estaciones_onu: %w", err)
+	}
+
+	return change, nil
+}
+
+// saveOltConfig saves the running config of the olt with its driver
+func saveOltConfig(host models.HostInfo) error {
+	driver, err := getDriver(host.Vendor)
+	if err != nil {
+		return err
+	}
+	return driver.SaveConfig(host)
+}
+
+// restoreOnu provisions again the onu on the pon port of the olt where it was removed by a move that failed on the new olt,
+// the service is configured with the values of the move, the previous ones are not read from the olt.
+// the host items are re-pointed to the new index, or detached as on a delete if the onu cant be restored
+func restoreOnu(db models.ConnMysqlPgsql, fromHost models.HostInfo, change models.OnuChange, onu models.OnuProvision, moveErr error) (models.OnuChange, error) {
+	onu.HostId = fromHost.Id
+	onu.Port = change.Port
+
+	restored, err := provisionOnu(fromHost, onu)
+	if err != nil {
+		utils.Logline("error restoring onu on its previous port", fromHost.Ip.String(), fromHost.Name, onu.Sn, err)
+		if errSave := saveOnuChange(db, change); errSave != nil {
+			utils.Logline("error saving onu change", fromHost.Ip.String(), fromHost.Name, onu.Sn, errSave)
+		}
+		if errSync := onuPendingSync(db, fromHost, onu.OldId); errSync != nil {
+			utils.Logline("error updating estaciones_onu", fromHost.Ip.String(), fromHost.Name, onu.OldId, errSync)
+		}
+		return change, fmt.Errorf("%w, and it couldnt be restored on %s %s: %v", moveErr, fromHost.Name, change.Port, err)
+	}
+
+	change.Action = "restore"
+	change.ToHostId = fromHost.Id
+	change.ToPort = restored.Port
+	change.ToOnuId = restored.OnuId
+	if change.ToSnmpIndex, err = onuIndexByOldId(fromHost, onu.OldId); err != nil {
+		utils.Logline("error getting snmp index of the restored onu", fromHost.Ip.String(), fromHost.Name, onu.Sn, err)
+	}
+	if errSave := saveOnuChange(db, change); errSave != nil {
+		utils.Logline("error saving onu change", fromHost.Ip.String(), fromHost.Name, onu.Sn, errSave)
+	}
+	if errSync := onuPendingSync(db, fromHost, onu.OldId); errSync != nil {
+		utils.Logline("error updating estaciones_onu", fromHost.Ip.String(), fromHost.Name, onu.OldId, errSync)
+	}
+
+	return change, fmt.Errorf("%w, the onu was restored on %s %s", moveErr, fromHost.Name, restored.Interface)
+}
+
+// validOnuProvision checks every value of the onu sent to the olt, the sn is left in uppercase
+func validOnuProvision(onu *models.OnuProvision) error {
+	onu.Sn = strings.ToUpper(strings.TrimSpace(onu.Sn))
+	if !reOnuProvisionPort.MatchString(onu.Port) {
+		return fmt.Errorf("invalid pon port %q, expected shelf/slot/port", onu.Port)
+	}
+	if !reOnuProvisionSn.MatchString(onu.Sn) {
+		return fmt.Errorf("invalid sn %q", onu.Sn)
+	}
+	if !reCliToken.MatchString(onu.OnuType) {
+		return fmt.Errorf("invalid onu_type %q", onu.OnuType)
+	}
+	if !reCliToken.MatchString(onu.Profile) {
+		return fmt.Errorf("invalid profile %q", onu.Profile)
+	}
+	if !reOldId.MatchString(onu.OldId) {
+		return fmt.Errorf("invalid oldid %q", onu.OldId)
+	}
+	if onu.Vlan < 1 || onu.Vlan > 4094 {
+		return fmt.Errorf("invalid vlan %d", onu.Vlan)
+	}
+	if strings.Trim(reCliTokenChars.ReplaceAllString(onu.Name, "_"), "_") == "" {
+		return fmt.Errorf("invalid name %q", onu.Name)
+	}
+	return nil
+}
+
+// provisionOnu runs the driver of the olt to authorize and configure the onu
+func provisionOnu(host models.HostInfo, onu models.OnuProvision) (models.OnuProvisioned, error) {
+	driver, err := getDriver(host.Vendor)
+	if err != nil {
+		return models.OnuProvisioned{}, err
+	}
+
+	onuId, err := driver.ProvisionOnu(host, onu)
+	if err != nil {
+		if errors.Is(err, errDriverUnsupported) {
+			return models.OnuProvisioned{}, fmt.Errorf("provisioning of onus is not supported on %s olts", host.Vendor)
+		}
+		utils.Logline("error provisioning onu", host.Ip.String(), host.Name, onu.Sn, err)
+		return models.OnuProvisioned{}, err
+	}
+
+	result := models.OnuProvisioned{
+		HostId:    host.Id,
+		Port:      onu.Port,
+		OnuId:     onuId,
+		Interface: fmt.Sprintf("gpon-onu_%s:%d", onu.Port, onuId),
+		Name:      onuProvisionName(onu),
+		Sn:        onu.Sn,
+	}
+	utils.Logline("onu provisioned", host.Ip.String(), host.Name, result.Interface, result.Name, result.Sn)
+
+	return result, nil
+}
+
+// deprovisionOnu runs the driver of the olt to remove the onu and returns the change with the index it had
+func deprovisionOnu(db models.ConnMysqlPgsql, host models.HostInfo, sn string, oldId string) (models.OnuChange, error) {
+	driver, err := getDriver(host.Vendor)
+	if err != nil {
+		return models.OnuChange{}, err
+	}
+
+	//the index is read before removing the onu, get_onu_info can run meanwhile
+	snmpIndex, err := onuItemIndex(db, host.Id, oldId)
+	if err != nil {
+		return models.OnuChange{}, fmt.Errorf("error getting items of the onu: %w", err)
+	}
+
+	port, onuId, err := driver.DeprovisionOnu(host, sn)
+	if err != nil {
+		if errors.Is(err, errDriverUnsupported) {
+			return models.OnuChange{}, fmt.Errorf("removing onus is not supported on %s olts", host.Vendor)
+		}
+		utils.Logline("error removing onu", host.Ip.String(), host.Name, sn, err)
+		return models.OnuChange{}, err
+	}
+	utils.Logline("onu removed", host.Ip.String(), host.Name, port, onuId, sn)
+
+	return models.OnuChange{Action: "delete", OldId: oldId, Sn: sn, HostId: host.Id, Port: port, OnuId: onuId, SnmpIndex: snmpIndex}, nil
+}
+
+// onuItemIndex returns the snmp index of the item onu-sn of the customer on the olt, empty if get_onu_info didnt create it
+func onuItemIndex(db models.ConnMysqlPgsql, hostId string, oldId string) (string, error) {
+	query := `SELECT sn FROM network.host_item
+		WHERE host_id=$1 AND oldid=$2 AND nombre='onu-sn' AND sn NOT LIKE 'removed-%'
+		ORDER BY activo DESC, id DESC LIMIT 1`
+	var snmpIndex string
+	err := db.ConnPgsql.QueryRow(db.Ctx, query, hostId, oldId).Scan(&snmpIndex)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+	return snmpIndex, nil
+}
+
+// onuIndexByOldId finds the snmp index of the onu named with oldId on the olt
+func onuIndexByOldId(host models.HostInfo, oldId string) (string, error) {
+	driver, err := getDriver(host.Vendor)
+	if err != nil {
+		return "", err
+	}
+
+	table, err := driver.OnuTable(host, []string{"onu-name"})
+	for _, onu := range table.column("onu-name") {
+		if onuOldId, errName := oldIdFromOnuName(onu.value); errName == nil && onuOldId == oldId {
+			return onu.snmpIndex, nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("there is no onu named with oldid %s", oldId)
+}
+
+// saveOnuChange re-points the host items of the onu to the new olt and index on a move, or detaches them on a delete,
+// and records the change on network.onu_change
+func saveOnuChange(db models.ConnMysqlPgsql, change models.OnuChange) error {
+	//create context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	//open a transaction
+	tx, err := db.ConnPgsql.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if change.ToSnmpIndex != "" {
+		//the items left on the new index by a previous onu are detached first
+		query := `UPDATE network.host_item SET activo=false, sn='removed-' || sn
+			WHERE host_id=$1 AND sn=$2 AND nombre LIKE 'onu-%' AND (oldid IS NULL OR oldid::text<>$3)`
+		if _, err := tx.Exec(ctx, query, change.ToHostId, change.ToSnmpIndex, change.OldId); err != nil {
+			return err
+		}
+
+		query = `UPDATE network.host_item SET activo=true, host_id=$1, sn=$2
+			WHERE host_id=$3 AND oldid=$4 AND nombre LIKE 'onu-%' AND sn NOT LIKE 'removed-%'`
+		if _, err := tx.Exec(ctx, query, change.ToHostId, change.ToSnmpIndex, change.HostId, change.OldId); err != nil {
+			return err
+		}
+	} else {
+		query := `UPDATE network.host_item SET activo=false, sn='removed-' || sn
+			WHERE host_id=$1 AND oldid=$2 AND nombre LIKE 'onu-%' AND sn NOT LIKE 'removed-%'`
+		if _, err := tx.Exec(ctx, query, change.HostId, change.OldId); err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO network.onu_change (action, oldid, sn, host_id, port, onu_id, snmp_index, to_host_id, to_port, to_onu_id, to_snmp_index, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::bigint, NULLIF($9, ''), NULLIF($10, 0), NULLIF($11, ''), now())`
+	if _, err := tx.Exec(ctx, query, change.Action, change.OldId, change.Sn, change.HostId, change.Port, change.OnuId, change.SnmpIndex,
+		change.ToHostId, change.ToPort, change.ToOnuId, change.ToSnmpIndex); err != nil {
+		return err
+	}
+
+	//commit transaction
+	return tx.Commit(ctx)
+}
+
+// onuPendingSync sets sync=0 on the row of estaciones_onu and points it to the doc_red of the olt, so the task olt_autowrite
+// saves the config of the olt where the onu is now. if the olt has no doc_red the previous one is kept
+func onuPendingSync(db models.ConnMysqlPgsql, host models.HostInfo, oldId string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	query := `UPDATE estaciones_onu
+		SET sync=0, docred_id=COALESCE((SELECT dr.id FROM doc_red as dr WHERE dr.ip=? ORDER BY dr.id LIMIT 1), docred_id)
+		WHERE id=?`
+	if _, err := db.ConnMysql.ExecContext(ctx, query, host.Ip.String(), oldId); err != nil {
+		utils.Logline("error updating sync onus on db:", host.Ip.String(), err)
+		return err
+	}
+	return nil
+}
+
+// onuProvisionName returns the name of the onu on the olt as <name>_-_<oldid>, the one parsed by oldIdFromOnuName,
+// the chars of the name not allowed on the cli are changed to _
+func onuProvisionName(onu models.OnuProvision) string {
+	return strings.Trim(reCliTokenChars.ReplaceAllString(onu.Name, "_"), "_") + "_-_" + onu.OldId
+}
@@ -0,0 +1,140 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+
+	"ired.com/olt/models"
+)
+
+func TestValidOnuProvision(t *testing.T) {
+	valid := models.OnuProvision{HostId: "12", Port: "1/2/1", Sn: " ztegc8a1b2c3 ", OnuType: "ZTE-F660", Profile: "100M", Vlan: 100, Name: "juan perez", OldId: "4521"}
+
+	tests := []struct {
+		name    string
+		change  func(onu *models.OnuProvision)
+		wantErr bool
+	}{
+		{"valid", func(onu *models.OnuProvision) {}, false},
+		{"port without shelf", func(onu *models.OnuProvision) { onu.Port = "2/1" }, true},
+		{"port with command", func(onu *models.OnuProvision) { onu.Port = "1/2/1\nreboot" }, true},
+		{"short sn", func(onu *models.OnuProvision) { onu.Sn = "ZTEGC8A1" }, true},
+		{"onu type with newline", func(onu *models.OnuProvision) { onu.OnuType = "ZTE-F660\nno onu 1" }, true},
+		{"onu type with space", func(onu *models.OnuProvision) { onu.OnuType = "ZTE F660" }, true},
+		{"profile with newline", func(onu *models.OnuProvision) { onu.Profile = "100M\nexit" }, true},
+		{"oldid not numeric", func(onu *models.OnuProvision) { onu.OldId = "12\nend" }, true},
+		{"vlan out of range", func(onu *models.OnuProvision) { onu.Vlan = 4095 }, true},
+		{"name without valid chars", func(onu *models.OnuProvision) { onu.Name = " \n\t" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onu := valid
+			tt.change(&onu)
+			err := validOnuProvision(&onu)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validOnuProvision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && onu.Sn != "ZTEGC8A1B2C3" {
+				t.Errorf("sn = %q, want it trimmed and in uppercase", onu.Sn)
+			}
+		})
+	}
+}
+
+func TestOnuProvisionName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"juan perez", "juan_perez_-_4521"},
+		{"  juan   perez  ", "juan_perez_-_4521"},
+		{"juan\nexit\nno onu 1", "juan_exit_no_onu_1_-_4521"},
+		{"josé; peña", "jos_pe_a_-_4521"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := onuProvisionName(models.OnuProvision{Name: tt.name, OldId: "4521"})
+			if got != tt.want {
+				t.Errorf("onuProvisionName() = %q, want %q", got, tt.want)
+			}
+			if oldId, err := oldIdFromOnuName(got); err != nil || oldId != "4521" {
+				t.Errorf("oldIdFromOnuName(%q) = %q, %v", got, oldId, err)
+			}
+		})
+	}
+}
+
+// recordDriver is a database/sql driver that only records the statements executed, to check the queries sent to mysql
+type recordDriver struct {
+	mu    sync.Mutex
+	execs []recordedExec
+}
+
+type recordedExec struct {
+	query string
+	args  []any
+}
+
+type recordConn struct{ driver *recordDriver }
+
+func (d *recordDriver) Open(name string) (driver.Conn, error) { return recordConn{driver: d}, nil }
+
+func (c recordConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c recordConn) Close() error              { return nil }
+func (c recordConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+
+func (c recordConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	exec := recordedExec{query: query}
+	for _, arg := range args {
+		exec.args = append(exec.args, arg.Value)
+	}
+	c.driver.mu.Lock()
+	c.driver.execs = append(c.driver.execs, exec)
+	c.driver.mu.Unlock()
+	return driver.RowsAffected(1), nil
+}
+
+var recordDb = &recordDriver{}
+
+func init() {
+	sql.Register("record", recordDb)
+}
+
+func TestOnuPendingSyncPointsToTheOlt(t *testing.T) {
+	conn, err := sql.Open("record", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	db := models.ConnMysqlPgsql{ConnMysql: conn, Ctx: context.Background()}
+
+	//the onu moved to the olt 10.1.1.2, its row must be saved by olt_autowrite of that olt
+	toHost := models.HostInfo{Id: "2", Ip: netip.MustParseAddr("10.1.1.2"), Name: "OLT-NORTE"}
+	if err := onuPendingSync(db, toHost, "1234"); err != nil {
+		t.Fatalf("onuPendingSync() error = %v", err)
+	}
+
+	recordDb.mu.Lock()
+	defer recordDb.mu.Unlock()
+	if len(recordDb.execs) != 1 {
+		t.Fatalf("onuPendingSync() executed %d statements, want 1", len(recordDb.execs))
+	}
+	exec := recordDb.execs[0]
+	query := strings.Join(strings.Fields(exec.query), " ")
+	for _, part := range []string{"UPDATE estaciones_onu", "sync=0", "docred_id=COALESCE((SELECT dr.id FROM doc_red as dr WHERE dr.ip=?", "WHERE id=?"} {
+		if !strings.Contains(query, part) {
+			t.Errorf("query %q doesnt contain %q", query, part)
+		}
+	}
+	if len(exec.args) != 2 || exec.args[0] != "10.1.1.2" || exec.args[1] != "1234" {
+		t.Errorf("args = %v, want [10.1.1.2 1234]", exec.args)
+	}
+}
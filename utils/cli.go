@@ -0,0 +1,337 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/reiver/go-telnet"
+	"ired.com/olt/models"
+)
+
+// CliConn is the connection with the olt, it can be telnet or ssh
+type CliConn interface {
+	Read(b []byte) (int, error)
+	Write(b []byte) (int, error)
+	Close() error
+	RemoteAddr() net.Addr
+}
+
+// CliLoginStep is one command sent after the login, {password} is replaced by the password of the olt
+type CliLoginStep struct {
+	Send   string
+	Expect string
+}
+
+// CliLoginScript has the prompts of every vendor and the commands to leave the session ready to work
+type CliLoginScript struct {
+	UserPrompt  string         // regex of the username prompt, only used on telnet
+	PassPrompt  string         // regex of the password prompt, only used on telnet
+	LoginPrompt string         // regex of the prompt shown after the login
+	Prompt      string         // regex of the prompt of the session after the steps
+	Pager       string         // regex of the pager, it is answered with a space
+	Errors      string         // regex of the errors printed by the olt
+	Timeout     time.Duration  // timeout of every step of the login
+	Steps       []CliLoginStep // commands sent after the login
+}
+
+// login scripts of every vendor
+var CliLoginScripts = map[string]CliLoginScript{
+	"zte": {
+		UserPrompt:  `(?i)username:\s*$`,
+		PassPrompt:  `(?i)password:\s*$`,
+		LoginPrompt: `\S+#\s*$`,
+		Prompt:      `\S+#\s*$`,
+		Pager:       `[ \t]*-+\s*(?i:more)[^\n]*?-+\s*$`,
+		Errors:      `Error|Invalid input`,
+		Timeout:     3 * time.Second,
+	},
+	"vsol": {
+		UserPrompt:  `(?i)login:\s*$`,
+		PassPrompt:  `(?i)password:\s*$`,
+		LoginPrompt: `\S+>\s*$`,
+		Prompt:      `\S+#\s*$`,
+		Pager:       `[ \t]*-+\s*(?i:more)[^\n]*?-+\s*$`,
+		Errors:      `Error|Invalid input|Unknown command`,
+		Timeout:     2 * time.Second,
+		Steps: []CliLoginStep{
+			{Send: "enable", Expect: `(?i)password:\s*$`},
+			{Send: "{password}", Expect: `\S+#\s*$`},
+			{Send: "conf t", Expect: `\S+#\s*$`}, //change to config mode
+		},
+	},
+	"cdata": {
+		UserPrompt:  `(?i)name:\s*$`,
+		PassPrompt:  `(?i)password:\s*$`,
+		LoginPrompt: `\S+>\s*$`,
+		Prompt:      `\S+#\s*$`,
+		Pager:       `[ \t]*-+\s*(?i:more)[^\n]*?-+\s*$`,
+		Errors:      `Error|Invalid input|Unknown command`,
+		Timeout:     4 * time.Second,
+		Steps: []CliLoginStep{
+			{Send: "enable", Expect: `\S+#\s*$`},
+			{Send: "config", Expect: `\S+#\s*$`}, //change to config mode
+		},
+	},
+}
+
+var reCliNoise = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]|[\x00\x08\r]`)
+
+// CliSession is an expect style session with the olt, it answers the pager and strips the echo and prompt of every response
+type CliSession struct {
+	conn    CliConn
+	prompt  *regexp.Regexp
+	pager   *regexp.Regexp
+	errors  *regexp.Regexp
+	chunks  chan []byte
+	readErr chan error
+	done    chan struct{}
+	buffer  []byte
+}
+
+// Function to open the cli session with the OLT using telnet or ssh and the login script of the vendor
+func OltCliConnect(vendor string, protocol string, host string, port string, username string, password string, sshOptions models.SshOptions) (*CliSession, error) {
+	script, ok := CliLoginScripts[vendor]
+	if !ok {
+		return nil, fmt.Errorf("there is no cli login script for vendor %s", vendor)
+	}
+
+	// validar primero si se le llega al equipo por ping
+	if err := PingHost(host, 3, 2); err != nil {
+		return nil, err
+	}
+
+	address := fmt.Sprintf("%s:%s", host, port)
+
+	var conn CliConn
+	var err error
+	if protocol == "ssh" {
+		conn, err = oltSshDial(address, username, password, sshOptions, script.Timeout)
+	} else {
+		conn, err = telnet.DialTo(address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to OLT %s: %v", vendor, err)
+	}
+
+	session := newCliSession(conn, script)
+	if err := session.login(protocol, username, password, script); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("failed to connect to OLT %s: %v", vendor, err)
+	}
+
+	return session, nil
+}
+
+func newCliSession(conn CliConn, script CliLoginScript) *CliSession {
+	session := &CliSession{
+		conn:    conn,
+		prompt:  regexp.MustCompile(script.Prompt),
+		chunks:  make(chan []byte, 64),
+		readErr: make(chan error, 1),
+		done:    make(chan struct{}),
+	}
+	if script.Pager != "" {
+		session.pager = regexp.MustCompile(script.Pager)
+	}
+	if script.Errors != "" {
+		session.errors = regexp.MustCompile(script.Errors)
+	}
+
+	//read the connection on background, the reads are buffered and not one byte at a time
+	go func() {
+		buffer := make([]byte, 4096)
+		for {
+			n, err := conn.Read(buffer)
+			if n > 0 {
+				chunk := make([]byte, n)
+				copy(chunk, buffer[:n])
+				select {
+				case session.chunks <- chunk:
+				case <-session.done:
+					return
+				}
+			}
+			if err != nil {
+				session.readErr <- err
+				return
+			}
+			if n == 0 {
+				session.readErr <- fmt.Errorf("no data received from [%s], connection may be closed", conn.RemoteAddr())
+				return
+			}
+		}
+	}()
+
+	return session
+}
+
+func (s *CliSession) login(protocol string, username string, password string, script CliLoginScript) error {
+	//on ssh the credentials go on the handshake
+	if protocol != "ssh" {
+		if _, err := s.expect(regexp.MustCompile(script.UserPrompt), script.Timeout); err != nil {
+			return fmt.Errorf("expected string was the username prompt: %v", err)
+		}
+		if _, err := s.SendExpect(username, script.PassPrompt, script.Timeout); err != nil {
+			return fmt.Errorf("error processing username: %v", err)
+		}
+		if _, err := s.SendExpect(password, script.LoginPrompt, script.Timeout); err != nil {
+			return fmt.Errorf("error processing password: %v", err)
+		}
+	} else if _, err := s.expect(regexp.MustCompile(script.LoginPrompt), script.Timeout); err != nil {
+		return fmt.Errorf("expected string was the prompt: %v", err)
+	}
+
+	for _, step := range script.Steps {
+		command := strings.ReplaceAll(step.Send, "{password}", password)
+		if _, err := s.SendExpect(command, step.Expect, script.Timeout); err != nil {
+			return fmt.Errorf("error processing login step '%s': %v", step.Send, err)
+		}
+	}
+
+	return nil
+}
+
+// Send writes the command and waits for the prompt, returns the output without the echo of the command and without the prompt
+func (s *CliSession) Send(command string, timeout time.Duration) (string, error) {
+	response, _, err := s.send(command, s.prompt, timeout)
+	return response, err
+}
+
+// SendExpect writes the command and waits for the regex expect instead of the prompt
+func (s *CliSession) SendExpect(command string, expect string, timeout time.Duration) (string, error) {
+	re, err := regexp.Compile(expect)
+	if err != nil {
+		return "", fmt.Errorf("invalid expect regex '%s': %w", expect, err)
+	}
+	response, _, err := s.send(command, re, timeout)
+	return response, err
+}
+
+// SendConfirm writes the command and waits for the prompt, if the olt asks the question confirm instead it is answered
+// with answer and the output of both is returned. confirm is checked on the last line, before it is stripped from the output
+func (s *CliSession) SendConfirm(command string, confirm string, answer string, timeout time.Duration) (string, error) {
+	question, err := regexp.Compile(confirm)
+	if err != nil {
+		return "", fmt.Errorf("invalid confirm regex '%s': %w", confirm, err)
+	}
+	expect, err := regexp.Compile("(?:" + confirm + ")|(?:" + s.prompt.String() + ")")
+	if err != nil {
+		return "", fmt.Errorf("invalid confirm regex '%s': %w", confirm, err)
+	}
+
+	response, last, err := s.send(command, expect, timeout)
+	if err != nil || !question.MatchString(last) {
+		return response, err
+	}
+
+	answered, err := s.Send(answer, timeout)
+	return strings.TrimSpace(response + "\n" + last + "\n" + answered), err
+}
+
+// send writes the command and returns the output without the echo and the last line, the last line is returned apart
+func (s *CliSession) send(command string, expect *regexp.Regexp, timeout time.Duration) (string, string, error) {
+	//if debug mode is on, log every string send to the OLT
+	if os.Getenv("GIN_MODE") == "debug" {
+		Logline(command)
+	}
+
+	if _, err := s.conn.Write([]byte(command + "\r\n")); err != nil {
+		return "", "", fmt.Errorf("error sending string [%s] to %s: %w", command, s.conn.RemoteAddr(), err)
+	}
+
+	response, err := s.expect(expect, timeout)
+	if err != nil {
+		return response, "", fmt.Errorf("failed on read response of [%s]: %w", command, err)
+	}
+
+	last := response
+	if pos := strings.LastIndex(response, "\n"); pos >= 0 {
+		last = response[pos+1:]
+	}
+	response = cliStripEcho(response, command)
+	if s.errors != nil && s.errors.MatchString(response) {
+		return response, last, fmt.Errorf("OLT returns error sending string '%s', response was: [%s]", command, response)
+	}
+
+	return response, last, nil
+}
+
+// expect reads until the regex matches the end of the output, the pager is answered with a space and removed from the output
+func (s *CliSession) expect(re *regexp.Regexp, timeout time.Duration) (string, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		if s.pager != nil {
+			if loc := s.pager.FindIndex(s.buffer); loc != nil {
+				s.buffer = append(s.buffer[:loc[0]], s.buffer[loc[1]:]...)
+				if _, err := s.conn.Write([]byte(" ")); err != nil {
+					return "", fmt.Errorf("error answering pager on %s - %w", s.conn.RemoteAddr(), err)
+				}
+			}
+		}
+
+		if loc := re.FindIndex(s.buffer); loc != nil {
+			response := string(s.buffer[:loc[1]])
+			s.buffer = s.buffer[loc[1]:]
+
+			//if debug mode is on, log every output from the OLT
+			if os.Getenv("GIN_MODE") == "debug" {
+				Logline(response)
+			}
+			return response, nil
+		}
+
+		select {
+		case chunk := <-s.chunks:
+			s.buffer = reCliNoise.ReplaceAll(append(s.buffer, chunk...), nil)
+		case err := <-s.readErr:
+			return string(s.buffer), fmt.Errorf("error on reading from %s - %w", s.conn.RemoteAddr(), err)
+		case <-deadline.C:
+			return string(s.buffer), fmt.Errorf("timeout on reading from %s, expected was '%s'", s.conn.RemoteAddr(), re.String())
+		}
+	}
+}
+
+// Close closes the connection with the olt
+func (s *CliSession) Close() error {
+	select {
+	case <-s.done:
+		return nil
+	default:
+		close(s.done)
+	}
+	return s.conn.Close()
+}
+
+// cliStripEcho removes the echo of the command on the first line and the prompt on the last line
+func cliStripEcho(response string, command string) string {
+	lines := strings.Split(response, "\n")
+	if len(lines) > 0 && command != "" && strings.Contains(lines[0], strings.TrimSpace(command)) {
+		lines = lines[1:]
+	}
+	if len(lines) > 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// OltCleanOutput removes double spaces and null chars from every line of the output and changes it to lowercase
+func OltCleanOutput(output string) string {
+	lines := strings.Split(output, "\n")
+	for key := range lines {
+		// remove double spaces
+		lines[key] = strings.ReplaceAll(lines[key], "  ", " ")
+
+		// Remove \x0d and \x00 from the string
+		lines[key] = strings.ReplaceAll(lines[key], "\x0d", "")
+		lines[key] = strings.ReplaceAll(lines[key], "\x00", "")
+
+		lines[key] = strings.ToLower(lines[key])
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"bufio"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCliLoginScripts(t *testing.T) {
+	tests := []struct {
+		vendor string
+		regex  string
+		text   string
+		want   bool
+	}{
+		{"zte", "prompt", "ZXAN#", true},
+		{"zte", "prompt", "OLT-CENTRO(config-if)# ", true},
+		{"zte", "prompt", "gpon-onu_1/2/1:5 enable", false},
+		{"zte", "user", "Username:", true},
+		{"zte", "pager", "gpon-onu_1/2/1:1\r\n --More-- ", true},
+		{"zte", "errors", "%Error 20203: Invalid onu type", true},
+		{"zte", "errors", "Invalid input detected at '^' marker.", true},
+		{"zte", "errors", "onu 5 type ZTE-F660 sn ZTEGC8A1B2C3", false},
+		{"vsol", "login", "OLT>", true},
+		{"vsol", "prompt", "OLT>", false},
+		{"vsol", "prompt", "OLT(config)#", true},
+		{"vsol", "user", "Login: ", true},
+		{"vsol", "errors", "% Unknown command.", true},
+		{"cdata", "user", "User name:", true},
+		{"cdata", "prompt", "OLT(config)# ", true},
+		{"cdata", "pager", "--- Enter Key To Continue ----", false},
+		{"cdata", "pager", "----- More ( Press 'Q' to quit ) -----", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.vendor+"/"+tt.regex+"/"+tt.text, func(t *testing.T) {
+			script := CliLoginScripts[tt.vendor]
+			regex := map[string]string{
+				"user":   script.UserPrompt,
+				"login":  script.LoginPrompt,
+				"prompt": script.Prompt,
+				"pager":  script.Pager,
+				"errors": script.Errors,
+			}[tt.regex]
+			if got := regexp.MustCompile(regex).MatchString(tt.text); got != tt.want {
+				t.Errorf("%s of %s matches %q = %v, want %v", tt.regex, tt.vendor, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeOlt answers every command read on conn with the outputs, a pager on the output waits for the space before sending the rest.
+// returns the commands received until then
+func fakeOlt(t *testing.T, conn net.Conn, outputs map[string][]string) func() []string {
+	t.Helper()
+	var mu sync.Mutex
+	var received []string
+	go func() {
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			command, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command = strings.TrimSpace(command)
+			mu.Lock()
+			received = append(received, command)
+			mu.Unlock()
+			pages, ok := outputs[command]
+			if !ok {
+				pages = []string{command + "\r\n% Unknown command.\r\nOLT#"}
+			}
+			for i, page := range pages {
+				if i > 0 {
+					if space, err := reader.ReadByte(); err != nil || space != ' ' {
+						return
+					}
+				}
+				if _, err := conn.Write([]byte(page)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, received...)
+	}
+}
+
+func TestCliSessionSend(t *testing.T) {
+	client, server := net.Pipe()
+	fakeOlt(t, server, map[string][]string{
+		"show gpon onu uncfg": {
+			"show gpon onu uncfg\r\nOnuIndex Sn State\r\ngpon-onu_1/2/1:1 ZTEGC8A1B2C3 unknown\r\n --More-- ",
+			"\x1b[10D\x08\x08gpon-onu_1/2/1:2 HWTC1A2B3C4D unknown\r\nOLT#",
+		},
+		"onu 5 type BAD sn ZTEGC8A1B2C3": {"onu 5 type BAD sn ZTEGC8A1B2C3\r\n%Error 20203: Invalid onu type\r\nOLT#"},
+	})
+	session := newCliSession(client, CliLoginScripts["zte"])
+	defer session.Close()
+
+	response, err := session.Send("show gpon onu uncfg", time.Second)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	want := "OnuIndex Sn State\ngpon-onu_1/2/1:1 ZTEGC8A1B2C3 unknown\ngpon-onu_1/2/1:2 HWTC1A2B3C4D unknown"
+	if strings.TrimSpace(response) != want {
+		t.Errorf("Send() = %q, want %q", response, want)
+	}
+
+	if _, err := session.Send("onu 5 type BAD sn ZTEGC8A1B2C3", time.Second); err == nil {
+		t.Errorf("Send() of a command rejected by the olt didnt return error")
+	}
+}
+
+func TestCliSessionSendConfirm(t *testing.T) {
+	client, server := net.Pipe()
+	received := fakeOlt(t, server, map[string][]string{
+		"pon-onu-mng gpon-onu_1/2/1:5": {"pon-onu-mng gpon-onu_1/2/1:5\r\nOLT(gpon-onu-mng 1/2/1:5)#"},
+		"reboot":                       {"reboot\r\nConfirm to reboot the ONU? [yes/no]:"},
+		"yes":                          {"yes\r\nOLT(gpon-onu-mng 1/2/1:5)#"},
+		"end":                          {"end\r\nOLT#"},
+	})
+	session := newCliSession(client, CliLoginScripts["zte"])
+	defer session.Close()
+
+	confirm := `(?i)\[yes/no\]\s*:?\s*$`
+	for _, command := range []string{"pon-onu-mng gpon-onu_1/2/1:5", "reboot", "end"} {
+		if _, err := session.SendConfirm(command, confirm, "yes", time.Second); err != nil {
+			t.Fatalf("SendConfirm(%q) error = %v", command, err)
+		}
+	}
+
+	want := []string{"pon-onu-mng gpon-onu_1/2/1:5", "reboot", "yes", "end"}
+	if got := received(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("commands received by the olt = %q, want %q", got, want)
+	}
+}
@@ -46,3 +46,14 @@ func Int64ToString(num int64) string {
 	// Convert int64 to string
 	return strconv.FormatInt(num, 10)
 }
+
+func StringToUint64(num string) uint64 {
+	// Convert string to uint64
+	uint64Value, err := strconv.ParseUint(num, 10, 64)
+	if err != nil {
+		Logline("Error converting string to uint64:", err)
+		return 0
+	}
+
+	return uint64Value
+}
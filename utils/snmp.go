@@ -1,15 +1,18 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	g "github.com/gosnmp/gosnmp"
+	"ired.com/olt/models"
 )
 
-func OltSnmpConnect(host string, community string, maxOids int, maxRepetitions uint32, validatePing bool) (*g.GoSNMP, error) {
+func OltSnmpConnect(host string, auth models.SnmpAuth, maxOids int, maxRepetitions uint32, validatePing bool) (*g.GoSNMP, error) {
 	// if true then check if ping is ok to host
 	if validatePing {
 		if err := PingHost(host, 3, 2); err != nil {
@@ -18,7 +21,7 @@ func OltSnmpConnect(host string, community string, maxOids int, maxRepetitions u
 	}
 
 	params := &g.GoSNMP{
-		Community:          community,
+		Community:          auth.Community,
 		Target:             host,
 		Port:               161,
 		Version:            g.Version2c,
@@ -29,6 +32,13 @@ func OltSnmpConnect(host string, community string, maxOids int, maxRepetitions u
 		ExponentialTimeout: true,
 		Logger:             g.NewLogger(log.New(os.Stdout, "", 0)),
 	}
+	//if the olt has an user of snmpv3 use it instead of the community
+	if auth.User != "" {
+		if err := snmpV3Params(params, auth); err != nil {
+			return nil, err
+		}
+	}
+
 	err := params.Connect()
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to snmp: %v", err)
@@ -37,5 +47,239 @@ func OltSnmpConnect(host string, community string, maxOids int, maxRepetitions u
 	return params, nil
 }
 
+// snmpV3Params sets the user security model of snmpv3, authPriv if there is a priv password and authNoPriv if there is only an auth password
+func snmpV3Params(params *g.GoSNMP, auth models.SnmpAuth) error {
+	security, msgFlags, err := SnmpV3Security(auth)
+	if err != nil {
+		return err
+	}
+
+	params.Version = g.Version3
+	params.SecurityModel = g.UserSecurityModel
+	params.SecurityParameters = security
+	params.MsgFlags = msgFlags
+
+	return nil
+}
+
+// SnmpV3Security returns the user security parameters of snmpv3 and the security level of the credentials of the olt
+func SnmpV3Security(auth models.SnmpAuth) (*g.UsmSecurityParameters, g.SnmpV3MsgFlags, error) {
+	authProtocols := map[string]g.SnmpV3AuthProtocol{
+		"md5":    g.MD5,
+		"sha":    g.SHA,
+		"sha224": g.SHA224,
+		"sha256": g.SHA256,
+		"sha384": g.SHA384,
+		"sha512": g.SHA512,
+	}
+	privProtocols := map[string]g.SnmpV3PrivProtocol{
+		"des":    g.DES,
+		"aes":    g.AES,
+		"aes192": g.AES192,
+		"aes256": g.AES256,
+	}
+
+	security := &g.UsmSecurityParameters{UserName: auth.User}
+	msgFlags := g.NoAuthNoPriv
+
+	if auth.AuthPassword != "" {
+		protocol, ok := authProtocols[strings.ToLower(auth.AuthProtocol)]
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown snmpv3 auth protocol: %s", auth.AuthProtocol)
+		}
+		security.AuthenticationProtocol = protocol
+		security.AuthenticationPassphrase = auth.AuthPassword
+		msgFlags = g.AuthNoPriv
+
+		if auth.PrivPassword != "" {
+			protocol, ok := privProtocols[strings.ToLower(auth.PrivProtocol)]
+			if !ok {
+				return nil, 0, fmt.Errorf("unknown snmpv3 priv protocol: %s", auth.PrivProtocol)
+			}
+			security.PrivacyProtocol = protocol
+			security.PrivacyPassphrase = auth.PrivPassword
+			msgFlags = g.AuthPriv
+		}
+	}
+
+	return security, msgFlags, nil
+}
+
+// SnmpWalker streams the rows of a table walked with paginated GetBulk, read Rows until it is closed and then check Err
+type SnmpWalker struct {
+	Rows      <-chan g.SnmpPDU
+	Retrieved int // rows sent on Rows, read it after Err. if Err is not nil they are a partial result
+	err       error
+	done      chan struct{}
+}
+
+// Err waits for the end of the walk and returns why it stopped before the end of the table, nil if the table was completed
+func (w *SnmpWalker) Err() error {
+	<-w.done
+	return w.err
+}
+
+// SnmpWalk walks the table under oid requesting conn.MaxRepetitions rows per GetBulk, it ends when the olt returns
+// endOfMibView, noSuchObject or an oid outside of the table, after limit rows (0 without limit) or when ctx is done.
+// every page that fails is retried up to pageRetries times before giving up
+func SnmpWalk(ctx context.Context, conn *g.GoSNMP, oid string, limit int, pageRetries int) *SnmpWalker {
+	rows := make(chan g.SnmpPDU, conn.MaxRepetitions)
+	walker := &SnmpWalker{Rows: rows, done: make(chan struct{})}
+
+	go func() {
+		defer close(walker.done)
+		defer close(rows)
+
+		prefix := strings.TrimSuffix(oid, ".") + "."
+		next := oid
+		for limit == 0 || walker.Retrieved < limit {
+			if err := ctx.Err(); err != nil {
+				walker.err = fmt.Errorf("walk of %s stopped after %d rows: %w", oid, walker.Retrieved, err)
+				return
+			}
+
+			result, err := snmpGetBulkPage(ctx, conn, []string{next}, pageRetries)
+			if err != nil {
+				walker.err = fmt.Errorf("walk of %s stopped after %d rows: %w", oid, walker.Retrieved, err)
+				return
+			}
+			if len(result.Variables) == 0 {
+				return
+			}
+
+			for _, value := range result.Variables {
+				switch value.Type {
+				case g.EndOfMibView, g.NoSuchObject, g.NoSuchInstance:
+					return
+				}
+				if !strings.HasPrefix(value.Name, prefix) {
+					return // left the table
+				}
+				if value.Name == next {
+					walker.err = fmt.Errorf("walk of %s stopped after %d rows: olt returned the same oid %s", oid, walker.Retrieved, next)
+					return
+				}
+
+				select {
+				case rows <- value:
+				case <-ctx.Done():
+					walker.err = fmt.Errorf("walk of %s stopped after %d rows: %w", oid, walker.Retrieved, ctx.Err())
+					return
+				}
+				walker.Retrieved++
+				next = value.Name // oid for the next GetBulk request
+
+				if limit > 0 && walker.Retrieved >= limit {
+					return
+				}
+			}
+		}
+	}()
+
+	return walker
+}
+
+// SnmpTable walks several columns of the same table at once, every GetBulk asks for the next rows of the columns not finished yet,
+// so conn.MaxOids must allow len(columns). returns the pdus keyed by index and by the oid of the column without trailing dot, if err is not nil the rows are a partial result
+func SnmpTable(ctx context.Context, conn *g.GoSNMP, columns []string, pageRetries int) (map[string]map[string]g.SnmpPDU, error) {
+	return snmpTableWalk(ctx, columns, func(oids []string) ([]g.SnmpPDU, error) {
+		result, err := snmpGetBulkPage(ctx, conn, oids, pageRetries)
+		if err != nil {
+			return nil, err
+		}
+		return result.Variables, nil
+	})
+}
+
+// snmpTableWalk joins by index the pages of the columns returned by getPage, every page has the next rows of the oids requested interleaved
+func snmpTableWalk(ctx context.Context, columns []string, getPage func(oids []string) ([]g.SnmpPDU, error)) (map[string]map[string]g.SnmpPDU, error) {
+	rows := map[string]map[string]g.SnmpPDU{}
+
+	tables := make([]string, len(columns))
+	next := make([]string, len(columns))
+	active := make([]int, len(columns))
+	for i, column := range columns {
+		tables[i] = strings.TrimSuffix(column, ".")
+		next[i] = tables[i]
+		active[i] = i
+	}
+
+	var retrieved int
+	for len(active) > 0 {
+		if err := ctx.Err(); err != nil {
+			return rows, fmt.Errorf("table walk stopped after %d values: %w", retrieved, err)
+		}
+
+		oids := make([]string, len(active))
+		for i, column := range active {
+			oids[i] = next[column]
+		}
+		variables, err := getPage(oids)
+		if err != nil {
+			return rows, fmt.Errorf("table walk stopped after %d values: %w", retrieved, err)
+		}
+		if len(variables) == 0 {
+			break
+		}
+
+		//the varbinds come row by row, one per requested column
+		finished := map[int]bool{}
+		for i, value := range variables {
+			column := active[i%len(active)]
+			if finished[column] {
+				continue
+			}
+			switch value.Type {
+			case g.EndOfMibView, g.NoSuchObject, g.NoSuchInstance:
+				finished[column] = true
+				continue
+			}
+			if !strings.HasPrefix(value.Name, tables[column]+".") || value.Name == next[column] {
+				finished[column] = true // left the column
+				continue
+			}
+
+			index := strings.TrimPrefix(value.Name, tables[column]+".")
+			if rows[index] == nil {
+				rows[index] = map[string]g.SnmpPDU{}
+			}
+			rows[index][tables[column]] = value
+			next[column] = value.Name // oid for the next GetBulk request
+			retrieved++
+		}
+
+		var pending []int
+		for _, column := range active {
+			if !finished[column] {
+				pending = append(pending, column)
+			}
+		}
+		active = pending
+	}
+
+	return rows, nil
+}
+
+// snmpGetBulkPage requests one page of the walk, retrying if it fails
+func snmpGetBulkPage(ctx context.Context, conn *g.GoSNMP, oids []string, retries int) (*g.SnmpPacket, error) {
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(time.Duration(attempt) * 500 * time.Millisecond):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		var result *g.SnmpPacket
+		result, err = conn.GetBulk(oids, 0, conn.MaxRepetitions)
+		if err == nil {
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("error performing GetBulk on %v: %w", oids, err)
+}
+
 // func SnmpConnectV2(host string, community string, validatePing bool) (*gi.SNMP, error) {
 // }
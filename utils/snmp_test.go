@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	g "github.com/gosnmp/gosnmp"
+)
+
+// oidLess compares two oids number by number
+func oidLess(a string, b string) bool {
+	partsA := strings.Split(strings.TrimPrefix(a, "."), ".")
+	partsB := strings.Split(strings.TrimPrefix(b, "."), ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numberA, _ := strconv.Atoi(partsA[i])
+		numberB, _ := strconv.Atoi(partsB[i])
+		if numberA != numberB {
+			return numberA < numberB
+		}
+	}
+	return len(partsA) < len(partsB)
+}
+
+// fakeGetBulk answers like an agent with the mib of values: for every repetition the next oid of every requested oid,
+// EndOfMibView after the last one. after failAfter pages it returns an error, never if it is 0
+func fakeGetBulk(values map[string]string, maxRepetitions int, failAfter int) func(oids []string) ([]g.SnmpPDU, error) {
+	var mib []string
+	for oid := range values {
+		mib = append(mib, oid)
+	}
+	sort.Slice(mib, func(i, j int) bool { return oidLess(mib[i], mib[j]) })
+
+	var pages int
+	return func(oids []string) ([]g.SnmpPDU, error) {
+		pages++
+		if failAfter > 0 && pages > failAfter {
+			return nil, errors.New("request timeout")
+		}
+		current := append([]string{}, oids...)
+		var result []g.SnmpPDU
+		for range maxRepetitions {
+			for i, oid := range current {
+				pos := sort.Search(len(mib), func(n int) bool { return oidLess(oid, mib[n]) })
+				if pos == len(mib) {
+					result = append(result, g.SnmpPDU{Name: oid, Type: g.EndOfMibView})
+					continue
+				}
+				current[i] = mib[pos]
+				result = append(result, g.SnmpPDU{Name: mib[pos], Type: g.OctetString, Value: values[mib[pos]]})
+			}
+		}
+		return result, nil
+	}
+}
+
+func TestSnmpTableWalk(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    map[string]string
+		columns   []string
+		pageSize  int
+		failAfter int
+		want      map[string]map[string]string
+		wantErr   bool
+	}{
+		{
+			name: "columns of the same length",
+			values: map[string]string{
+				".1.3.6.1.2.1": "name1", ".1.3.6.1.2.2": "name2", ".1.3.6.1.2.3": "name3",
+				".1.3.6.1.4.1": "4", ".1.3.6.1.4.2": "7", ".1.3.6.1.4.3": "2",
+			},
+			columns:  []string{".1.3.6.1.2", ".1.3.6.1.4."},
+			pageSize: 2,
+			want: map[string]map[string]string{
+				"1": {".1.3.6.1.2": "name1", ".1.3.6.1.4": "4"},
+				"2": {".1.3.6.1.2": "name2", ".1.3.6.1.4": "7"},
+				"3": {".1.3.6.1.2": "name3", ".1.3.6.1.4": "2"},
+			},
+		},
+		{
+			name: "column shorter than the others and next table",
+			values: map[string]string{
+				".1.3.6.1.2.268501248.1": "name1", ".1.3.6.1.2.268501248.2": "name2", ".1.3.6.1.2.268501504.1": "name3",
+				".1.3.6.1.3.268501248.2": "ZTEGC8A1B2C3",
+				".1.3.6.1.4.268501248.1": "4",
+			},
+			columns:  []string{".1.3.6.1.2", ".1.3.6.1.3"},
+			pageSize: 2,
+			want: map[string]map[string]string{
+				"268501248.1": {".1.3.6.1.2": "name1"},
+				"268501248.2": {".1.3.6.1.2": "name2", ".1.3.6.1.3": "ZTEGC8A1B2C3"},
+				"268501504.1": {".1.3.6.1.2": "name3"},
+			},
+		},
+		{
+			name:     "end of mib",
+			values:   map[string]string{".1.3.6.1.2.1": "name1", ".1.3.6.1.2.2": "name2"},
+			columns:  []string{".1.3.6.1.2"},
+			pageSize: 10,
+			want: map[string]map[string]string{
+				"1": {".1.3.6.1.2": "name1"},
+				"2": {".1.3.6.1.2": "name2"},
+			},
+		},
+		{
+			name: "error keeps the rows read",
+			values: map[string]string{
+				".1.3.6.1.2.1": "name1", ".1.3.6.1.2.2": "name2", ".1.3.6.1.2.3": "name3",
+			},
+			columns:   []string{".1.3.6.1.2"},
+			pageSize:  2,
+			failAfter: 1,
+			want: map[string]map[string]string{
+				"1": {".1.3.6.1.2": "name1"},
+				"2": {".1.3.6.1.2": "name2"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := snmpTableWalk(context.Background(), tt.columns, fakeGetBulk(tt.values, tt.pageSize, tt.failAfter))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("snmpTableWalk() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := map[string]map[string]string{}
+			for index, row := range rows {
+				got[index] = map[string]string{}
+				for column, value := range row {
+					got[index][column] = value.Value.(string)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("snmpTableWalk() = %v, want %v", got, tt.want)
+			}
+			for index, row := range tt.want {
+				if len(got[index]) != len(row) {
+					t.Fatalf("row %s = %v, want %v", index, got[index], row)
+				}
+				for column, value := range row {
+					if got[index][column] != value {
+						t.Errorf("row %s column %s = %q, want %q", index, column, got[index][column], value)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"ired.com/olt/models"
+)
+
+// the known_hosts file is read and written by every connection
+var knownHostsMu sync.Mutex
+
+// sshConn is a shell over ssh that behaves like the telnet connection, so it can be used by CliSession
+type sshConn struct {
+	client  *ssh.Client
+	session *ssh.Session
+	stdin   io.WriteCloser
+	stdout  io.Reader
+}
+
+func (c *sshConn) Read(b []byte) (int, error) {
+	return c.stdout.Read(b)
+}
+
+func (c *sshConn) Write(b []byte) (int, error) {
+	return c.stdin.Write(b)
+}
+
+func (c *sshConn) Close() error {
+	c.session.Close()
+	return c.client.Close()
+}
+
+func (c *sshConn) RemoteAddr() net.Addr {
+	return c.client.RemoteAddr()
+}
+
+// Function to open an interactive shell on the OLT via ssh
+func oltSshDial(address string, username string, password string, options models.SshOptions, timeout time.Duration) (*sshConn, error) {
+	config := &ssh.ClientConfig{
+		User: username,
+		Auth: []ssh.AuthMethod{
+			ssh.Password(password),
+			// some olts only accept keyboard-interactive, answer every question with the password
+			ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = password
+				}
+				return answers, nil
+			}),
+		},
+		HostKeyCallback: sshHostKeyCallback(options.HostKey),
+		Timeout:         timeout,
+	}
+	config.KeyExchanges = []string{
+		"curve25519-sha256", "ecdh-sha2-nistp256", "diffie-hellman-group14-sha256", "diffie-hellman-group14-sha1",
+	}
+	config.Ciphers = []string{
+		"aes128-gcm@openssh.com", "aes128-ctr", "aes192-ctr", "aes256-ctr",
+	}
+	// some olts run old versions of ssh, the legacy algorithms are enabled only on them
+	if options.Legacy {
+		config.KeyExchanges = append(config.KeyExchanges, "diffie-hellman-group1-sha1")
+		config.Ciphers = append(config.Ciphers, "aes128-cbc", "3des-cbc")
+	}
+
+	client, err := ssh.Dial("tcp", address, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to OLT via ssh: %v", err)
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to open ssh session: %v", err)
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		client.Close()
+		return nil, fmt.Errorf("failed to open ssh stdin: %v", err)
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		client.Close()
+		return nil, fmt.Errorf("failed to open ssh stdout: %v", err)
+	}
+
+	//the cli of the olts needs a terminal
+	if err = session.RequestPty("vt100", 0, 200, ssh.TerminalModes{ssh.ECHO: 1}); err != nil {
+		session.Close()
+		client.Close()
+		return nil, fmt.Errorf("failed to request pty: %v", err)
+	}
+	if err = session.Shell(); err != nil {
+		session.Close()
+		client.Close()
+		return nil, fmt.Errorf("failed to start ssh shell: %v", err)
+	}
+
+	return &sshConn{client: client, session: session, stdin: stdin, stdout: stdout}, nil
+}
+
+// sshHostKeyCallback checks the key of the olt against the pinned fingerprint, or against the known_hosts file when there is none.
+// an olt not found on the file is trusted on first use and added to it, a key that changed is rejected until its line is removed
+func sshHostKeyCallback(pinned string) ssh.HostKeyCallback {
+	if pinned != "" {
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if fingerprint := ssh.FingerprintSHA256(key); fingerprint != pinned {
+				return fmt.Errorf("ssh host key of %s is %s, expected %s", hostname, fingerprint, pinned)
+			}
+			return nil
+		}
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		knownHostsMu.Lock()
+		defer knownHostsMu.Unlock()
+
+		file := os.Getenv("SSH_KNOWN_HOSTS")
+		if file == "" {
+			file = "known_hosts"
+		}
+		return knownHostCheck(file, hostname, remote, key)
+	}
+}
+
+// knownHostCheck checks the key on the known_hosts file and adds it if the host is not there
+func knownHostCheck(file string, hostname string, remote net.Addr, key ssh.PublicKey) error {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening known hosts %s: %w", file, err)
+	}
+	defer f.Close()
+
+	check, err := knownhosts.New(file)
+	if err != nil {
+		return fmt.Errorf("error reading known hosts %s: %w", file, err)
+	}
+
+	err = check(hostname, remote, key)
+	var keyErr *knownhosts.KeyError
+	if !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+		if keyErr != nil {
+			return fmt.Errorf("ssh host key of %s changed to %s, remove its line from %s if it is expected: %w", hostname, ssh.FingerprintSHA256(key), file, err)
+		}
+		return err
+	}
+
+	//trust on first use
+	if _, err := f.WriteString(knownhosts.Line([]string{hostname}, key) + "\n"); err != nil {
+		return fmt.Errorf("error writing known hosts %s: %w", file, err)
+	}
+	Logline("new ssh host key trusted", hostname, ssh.FingerprintSHA256(key))
+
+	return nil
+}
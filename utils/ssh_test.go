@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKnownHostCheck(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "known_hosts")
+	remote := &net.TCPAddr{IP: net.ParseIP("10.1.1.1"), Port: 22}
+	key := newTestHostKey(t)
+
+	//first use is trusted and stored
+	if err := knownHostCheck(file, "10.1.1.1:22", remote, key); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := knownHostCheck(file, "10.1.1.1:22", remote, key); err != nil {
+		t.Fatalf("same key: %v", err)
+	}
+
+	//a changed key is rejected
+	if err := knownHostCheck(file, "10.1.1.1:22", remote, newTestHostKey(t)); err == nil {
+		t.Fatal("changed key was accepted")
+	}
+
+	//other olts are trusted on their own first use
+	other := &net.TCPAddr{IP: net.ParseIP("10.1.1.2"), Port: 2222}
+	if err := knownHostCheck(file, "10.1.1.2:2222", other, newTestHostKey(t)); err != nil {
+		t.Fatalf("first use of other olt: %v", err)
+	}
+}
+
+func TestSshHostKeyCallbackPinned(t *testing.T) {
+	key := newTestHostKey(t)
+	remote := &net.TCPAddr{IP: net.ParseIP("10.1.1.1"), Port: 22}
+
+	if err := sshHostKeyCallback(ssh.FingerprintSHA256(key))("10.1.1.1:22", remote, key); err != nil {
+		t.Errorf("pinned key rejected: %v", err)
+	}
+	if err := sshHostKeyCallback(ssh.FingerprintSHA256(key))("10.1.1.1:22", remote, newTestHostKey(t)); err == nil {
+		t.Error("key different from the pinned one was accepted")
+	}
+}